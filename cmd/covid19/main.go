@@ -22,10 +22,15 @@ func main() {
 	rand.Seed(time.Now().Unix())
 
 	// Setup the commands
+	diffHandler := commands.NewDiffCommandHandler()
 	graphHandler := commands.NewGraphCommandHandler()
+	importHandler := commands.NewImportCommandHandler()
+	infoHandler := commands.NewInfoCommandHandler()
 	listHandler := commands.NewListCommandHandler()
 	predictHandler := commands.NewPredictCommandHandler()
+	snapshotsHandler := commands.NewSnapshotsCommandHandler()
 	updateHandler := commands.NewUpdateCommandHandler()
+	validateHandler := commands.NewValidateCommandHandler()
 
 	// Setup the application
 	app := &cli.App{
@@ -42,12 +47,45 @@ func main() {
 				Value:    false,
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:     "dataDir",
+				Usage:    "The directory that datasets are stored in. Overrides $COVID19_DATA_DIR.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "source",
+				Aliases:  []string{"s"},
+				Usage:    "The data source: owid, jhu, ecdc or local:[path].",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "asOf",
+				Aliases:  []string{"as-of"},
+				Usage:    "Load the dataset snapshot that was current at the given date (YYYY-MM-DD) or time (RFC 3339).",
+				Required: false,
+			},
+			&cli.BoolFlag{
+				Name:     "lenient",
+				Usage:    "Skip rows that can't be parsed with a warning instead of failing.",
+				Value:    false,
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "config",
+				Usage:    "The path of the configuration file. Overrides $COVID19_CONFIG.",
+				Required: false,
+			},
 		},
 		Commands: []*cli.Command{
+			diffHandler.Command(),
 			graphHandler.Command(),
+			importHandler.Command(),
+			infoHandler.Command(),
 			listHandler.Command(),
 			predictHandler.Command(),
+			snapshotsHandler.Command(),
 			updateHandler.Command(),
+			validateHandler.Command(),
 		},
 		Before:                 before,
 		UseShortOptionHandling: true,
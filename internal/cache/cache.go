@@ -0,0 +1,242 @@
+// Package cache contains the binary cache of parsed datasets.
+package cache
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// FormatVersion is the version of the cache's format, which is a gob of the
+// key, the world's Table and the fingerprints of the rows it was parsed from.
+// Caches with a different version are ignored.
+const FormatVersion = 6
+
+// FileKey types identify the contents of a dataset file.
+type FileKey struct {
+
+	// The file's name.
+	Name string
+
+	// The file's size in bytes.
+	Size int64
+
+	// The file's modification time in nanoseconds since the Unix epoch.
+	ModTime int64
+
+	// The hex encoded SHA-256 hash of the file's contents.
+	Hash string
+}
+
+// Key types identify the inputs a world was parsed from.
+type Key struct {
+
+	// The version of the cache's format.
+	Version int
+
+	// The name of the data source.
+	Source string
+
+	// The parse options the world was parsed with.
+	Options string
+
+	// The dataset files the world was parsed from.
+	Files []FileKey
+}
+
+// MARK: Initializers
+
+// NewKey creates and returns the key of the given files. The hashes of files
+// whose size and modification time match the given previous key are reused
+// instead of being recomputed.
+func NewKey(source string, options string, paths []string, previous *Key) (*Key, error) {
+	key := &Key{
+		Version: FormatVersion,
+		Source:  source,
+		Options: options,
+	}
+
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fileKey := FileKey{
+			Name:    filepath.Base(path),
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		}
+
+		if previous != nil && i < len(previous.Files) &&
+			previous.Files[i].Name == fileKey.Name &&
+			previous.Files[i].Size == fileKey.Size &&
+			previous.Files[i].ModTime == fileKey.ModTime {
+			fileKey.Hash = previous.Files[i].Hash
+		} else if fileKey.Hash, err = hashFile(path); err != nil {
+			return nil, err
+		}
+
+		key.Files = append(key.Files, fileKey)
+	}
+
+	return key, nil
+}
+
+// MARK: Exported functions
+
+// ReadKey reads the key of the cache at path.
+func ReadKey(path string) (*Key, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	key := &Key{}
+	if err = gob.NewDecoder(bufio.NewReader(file)).Decode(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Load loads the world from the cache at path if its key equals the given
+// key.
+func Load(path string, key *Key) (*models.World, bool) {
+	cachedKey, world, _, err := read(path, key, false)
+	if err != nil || !cachedKey.Equal(key) {
+		return nil, false
+	}
+
+	return world, true
+}
+
+// Read reads the key, world and row fingerprints of the cache at path
+// regardless of its key.
+func Read(path string) (*Key, *models.World, models.Fingerprints, error) {
+	return read(path, nil, true)
+}
+
+// Save writes the world and the fingerprints of the rows it was parsed from
+// to the cache at path with the given key. The cache is written to a
+// temporary file that replaces path once complete.
+func Save(path string, key *Key, world *models.World, fingerprints models.Fingerprints) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	out, err := ioutil.TempFile(filepath.Dir(path), ".cache-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	writer := bufio.NewWriter(out)
+	encoder := gob.NewEncoder(writer)
+
+	if err = encoder.Encode(key); err == nil {
+		err = encoder.Encode(models.NewTable(world))
+	}
+
+	if err == nil {
+		if fingerprints == nil {
+			fingerprints = make(models.Fingerprints)
+		}
+		err = encoder.Encode(fingerprints)
+	}
+
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(out.Name(), path)
+}
+
+// MARK: Exported methods
+
+// Equal returns whether or not the keys identify the same inputs.
+func (k Key) Equal(other *Key) bool {
+	if other == nil ||
+		k.Version != other.Version ||
+		k.Source != other.Source ||
+		k.Options != other.Options ||
+		len(k.Files) != len(other.Files) {
+		return false
+	}
+
+	for i, file := range k.Files {
+		if file.Name != other.Files[i].Name || file.Hash != other.Files[i].Hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MARK: Unexported functions
+
+// read reads the cache at path. Unless all is true, reading stops after the
+// key if it doesn't equal the given key, and the fingerprints aren't read.
+func read(path string, key *Key, all bool) (*Key, *models.World, models.Fingerprints, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer file.Close()
+
+	decoder := gob.NewDecoder(bufio.NewReader(file))
+
+	cachedKey := &Key{}
+	if err = decoder.Decode(cachedKey); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !all && !cachedKey.Equal(key) {
+		return cachedKey, nil, nil, nil
+	}
+
+	table := &models.Table{}
+	if err = decoder.Decode(table); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var fingerprints models.Fingerprints
+	if all {
+		if err = decoder.Decode(&fingerprints); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return cachedKey, table.World(), fingerprints, nil
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file at path.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := tempDir(t)
+	path := writeDataset(t, dir, 5, 30)
+	cachePath := filepath.Join(dir, "owid.world")
+
+	world, err := models.NewWorldFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewKey("owid", "", []string{path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Save(cachePath, key, world, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, ok := Load(cachePath, key)
+	if !ok {
+		t.Fatal("expected the cache to load")
+	}
+
+	if !reflect.DeepEqual(loaded, world) {
+		t.Error("expected the loaded world to equal the saved world")
+	}
+
+	// A different key misses
+	other := *key
+	other.Options = "lenient"
+	if _, ok = Load(cachePath, &other); ok {
+		t.Error("expected a different key to miss")
+	}
+}
+
+func TestNewKeyReusesHashes(t *testing.T) {
+	dir := tempDir(t)
+	path := writeDataset(t, dir, 1, 2)
+
+	key, err := NewKey("owid", "", []string{path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An unchanged file reuses the previous hash, even a wrong one
+	previous := *key
+	previous.Files = []FileKey{key.Files[0]}
+	previous.Files[0].Hash = "previous"
+
+	reused, err := NewKey("owid", "", []string{path}, &previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reused.Files[0].Hash != "previous" {
+		t.Errorf("expected the previous hash to be reused, got %q", reused.Files[0].Hash)
+	}
+
+	if !key.Equal(key) || key.Equal(reused) {
+		t.Error("expected keys to be compared by their hashes")
+	}
+}
+
+// BenchmarkStartup compares parsing an OWID-sized dataset with loading its
+// world from the cache.
+func BenchmarkStartup(b *testing.B) {
+	dir := tempDir(b)
+	path := writeDataset(b, dir, 220, 900)
+	cachePath := filepath.Join(dir, "owid.world")
+
+	world, err := models.NewWorldFromPath(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	key, err := NewKey("owid", "", []string{path}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err = Save(cachePath, key, world, nil); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("csv", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := models.NewWorldFromPath(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cache", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, ok := Load(cachePath, key); !ok {
+				b.Fatal("expected the cache to load")
+			}
+		}
+	})
+}
+
+// tempDir creates a temporary directory that is removed when the test ends.
+func tempDir(t testing.TB) string {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// writeDataset writes an OWID-layout dataset of the given number of
+// locations and days to dir, and returns its path.
+func writeDataset(t testing.TB, dir string, locations int, days int) string {
+	path := filepath.Join(dir, "full_data.csv")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintln(writer, "date,location,new_cases,new_deaths,total_cases,total_deaths")
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for l := 0; l < locations; l++ {
+		totalCases, totalDeaths := 0, 0
+		for d := 0; d < days; d++ {
+			newCases, newDeaths := (l+d)%97, (l+d)%7
+			totalCases += newCases
+			totalDeaths += newDeaths
+			fmt.Fprintf(writer, "%s,Location %03d,%d,%d,%d,%d\n", start.AddDate(0, 0, d).Format("2006-01-02"), l, newCases, newDeaths, totalCases, totalDeaths)
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// Changes types record how the cached world changed when it was last
+// replaced, so that work derived from the world only has to be redone for
+// the locations that changed.
+type Changes struct {
+
+	// The time the cached world was replaced.
+	Time time.Time `json:"time"`
+
+	// The name of the data source.
+	Source string `json:"source"`
+
+	// The differences between the previous and the new world.
+	Diff *models.WorldDiff `json:"diff"`
+}
+
+// MARK: Exported functions
+
+// ChangesPath returns the path of the changes of the cache at path.
+func ChangesPath(path string) string {
+	return path + ".changes.json"
+}
+
+// ReadChanges reads the changes of the cache at path.
+func ReadChanges(path string) (*Changes, error) {
+	data, err := ioutil.ReadFile(ChangesPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{}
+	if err = json.Unmarshal(data, changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// WriteChanges writes the changes of the cache at path.
+func WriteChanges(path string, changes *Changes) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(ChangesPath(path), data, 0644)
+}
@@ -0,0 +1,263 @@
+// Package commands conatins the commands for the medina command line application.
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/colinc86/covid-19/internal/config"
+	"github.com/colinc86/covid-19/internal/models"
+	"github.com/colinc86/covid-19/internal/snapshots"
+	"github.com/colinc86/covid-19/internal/sources"
+	"github.com/urfave/cli/v2"
+)
+
+// DiffCommandHandler handles diff commands.
+type DiffCommandHandler struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+
+	// MARK: Private properties
+	format string
+}
+
+// MARK: Initializers
+
+// NewDiffCommandHandler creates and returns a new diff command handler.
+func NewDiffCommandHandler() *DiffCommandHandler {
+	return &DiffCommandHandler{
+		Name:    "diff",
+		Aliases: []string{"d"},
+		Usage:   "Compares two versions of the dataset.",
+		Description: `Compare two dataset files or snapshots and report added and removed
+		locations, new dates and every retroactively revised value.
+		
+		Each version is a file path, a snapshot id or "current" for the
+		current dataset. Without versions the two newest snapshots are
+		compared, and with one version it is compared to the current dataset.
+		
+		Examples:
+			# Compare the two newest snapshots
+			covid19 diff
+			
+			# Compare a snapshot to the current dataset
+			covid19 diff [id]
+			
+			# Compare two files as JSON
+			covid19 diff -f json [path] [path]`,
+	}
+}
+
+// MARK: Public methods
+
+// Command creates and returns the handler's command.
+func (h *DiffCommandHandler) Command() *cli.Command {
+	return &cli.Command{
+		Name:        h.Name,
+		Aliases:     h.Aliases,
+		Usage:       h.Usage,
+		Description: h.Description,
+		ArgsUsage:   "[old] [new]",
+		Action:      h.DiffAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "table or json",
+				Required:    false,
+				Value:       "table",
+				Destination: &h.format,
+			},
+		},
+	}
+}
+
+// DiffAction compares two versions of the dataset.
+func (h *DiffCommandHandler) DiffAction(c *cli.Context) error {
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	store := snapshots.NewStore(paths.SnapshotDir())
+
+	var oldSpec, newSpec string
+	switch c.NArg() {
+	case 0:
+		if oldSpec, newSpec, err = h.newestSnapshots(source, store); err != nil {
+			return err
+		}
+	case 1:
+		oldSpec, newSpec = c.Args().Get(0), "current"
+	case 2:
+		oldSpec, newSpec = c.Args().Get(0), c.Args().Get(1)
+	default:
+		return errors.New("expected at most two versions")
+	}
+
+	oldWorld, err := h.loadVersion(oldSpec, source, cfg, paths, store)
+	if err != nil {
+		return fmt.Errorf("%s: %v", oldSpec, err)
+	}
+
+	newWorld, err := h.loadVersion(newSpec, source, cfg, paths, store)
+	if err != nil {
+		return fmt.Errorf("%s: %v", newSpec, err)
+	}
+
+	diff := models.CompareWorlds(oldWorld, newWorld)
+
+	if h.format == "json" {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+		return nil
+	}
+
+	h.printDiff(oldSpec, newSpec, diff)
+	return nil
+}
+
+// MARK: Unexported methods
+
+// newestSnapshots returns the ids of the two newest snapshots of the data
+// source's file.
+func (h *DiffCommandHandler) newestSnapshots(source sources.DataSource, store *snapshots.Store) (string, string, error) {
+	files := source.Files()
+	if len(files) != 1 {
+		return "", "", fmt.Errorf("the %s data source doesn't have a single dataset file, give two versions to compare", source.Name())
+	}
+
+	list, err := store.List(files[0].Name)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(list) < 2 {
+		return "", "", fmt.Errorf("expected at least two snapshots of %s but found %d", files[0].Name, len(list))
+	}
+
+	return list[len(list)-2].ID(), list[len(list)-1].ID(), nil
+}
+
+// loadVersion loads the world of a file path, snapshot id or the current
+// dataset.
+func (h *DiffCommandHandler) loadVersion(spec string, source sources.DataSource, cfg *config.Config, paths *config.Paths, store *snapshots.Store) (*models.World, error) {
+	if spec == "current" {
+		return source.Parse(paths.DataDir, parseOptions(cfg))
+	}
+
+	if _, err := os.Stat(spec); err == nil {
+		return parseFile(source, cfg, spec)
+	}
+
+	snapshot, err := store.Find(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "covid19-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, snapshot.File)
+	if err = snapshot.Extract(path); err != nil {
+		return nil, err
+	}
+
+	return parseFile(source, cfg, path)
+}
+
+// printDiff prints the diff as tables.
+func (h *DiffCommandHandler) printDiff(oldSpec string, newSpec string, diff *models.WorldDiff) {
+	fmt.Printf("Comparing %s to %s\n\n", oldSpec, newSpec)
+
+	if diff.Empty() {
+		fmt.Println("No differences.")
+		return
+	}
+
+	fmt.Printf("%-20s %d %v\n", "Added locations", len(diff.AddedLocations), diff.AddedLocations)
+	fmt.Printf("%-20s %d %v\n", "Removed locations", len(diff.RemovedLocations), diff.RemovedLocations)
+	fmt.Printf("%-20s %d %v\n", "Updated locations", len(diff.UpdatedLocations), diff.UpdatedLocations)
+	fmt.Printf("%-20s %d %v\n", "New dates", len(diff.NewDates), diff.NewDates)
+	fmt.Printf("%-20s %d %v\n", "Removed dates", len(diff.RemovedDates), diff.RemovedDates)
+	fmt.Printf("%-20s %d\n", "Added records", diff.AddedRecords)
+	fmt.Printf("%-20s %d\n", "Removed records", diff.RemovedRecords)
+	fmt.Printf("%-20s %d\n", "Revised values", len(diff.Changes))
+
+	if len(diff.Changes) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%-32s %-12s %-22s %-12s %-12s %-12s\n", "Location", "Date", "Metric", "Old", "New", "Change")
+
+	for _, change := range diff.Changes {
+		delta := "n/a"
+		if change.Old != nil && change.New != nil {
+			delta = fmt.Sprintf("%+d", *change.New-*change.Old)
+		}
+
+		fmt.Printf("%-32s %-12s %-22s %-12s %-12s %-12s\n", change.Location, change.Date.Format("2006-01-02"), change.Metric.Name(), formatOptional(change.Old), formatOptional(change.New), delta)
+	}
+}
+
+// MARK: Unexported functions
+
+// parseFile parses a single dataset file. Files are parsed by the data source
+// if it reads a single file and with the default CSV layout otherwise.
+func parseFile(source sources.DataSource, cfg *config.Config, path string) (*models.World, error) {
+	files := source.Files()
+	if len(files) != 1 {
+		return models.NewWorldFromPathWithOptions(path, parseOptions(cfg))
+	}
+
+	if filepath.Base(path) == files[0].Name {
+		return source.Parse(filepath.Dir(path), parseOptions(cfg))
+	}
+
+	dir, err := ioutil.TempDir("", "covid19-file-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Symlink(absolutePath, filepath.Join(dir, files[0].Name)); err != nil {
+		return nil, err
+	}
+
+	return source.Parse(dir, parseOptions(cfg))
+}
+
+// formatOptional formats an optional value, or "n/a" if it is nil.
+func formatOptional(value *int) string {
+	if value == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%d", *value)
+}
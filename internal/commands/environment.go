@@ -0,0 +1,662 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/config"
+	"github.com/colinc86/covid-19/internal/fetch"
+	"github.com/colinc86/covid-19/internal/models"
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/snapshots"
+	"github.com/colinc86/covid-19/internal/sources"
+	"github.com/urfave/cli/v2"
+)
+
+// The maximum number of diagnostics printed after parsing.
+const maxPrintedDiagnostics = 5
+
+// loadPaths loads the configuration file and resolves the application's
+// paths from the global flags of the given context.
+func loadPaths(c *cli.Context) (*config.Config, *config.Paths, error) {
+	configFile := c.String("config")
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load configuration: %v", err)
+	}
+
+	if c.Bool("lenient") {
+		cfg.Lenient = true
+	}
+
+	return cfg, config.ResolvePaths(c.String("dataDir"), configFile, cfg), nil
+}
+
+// loadSource creates the data source selected by the global flags of the
+// given context or the configuration.
+func loadSource(c *cli.Context, cfg *config.Config) (sources.DataSource, error) {
+	spec := c.String("source")
+	if len(spec) == 0 {
+		spec = cfg.Source
+	}
+
+	return sources.New(spec)
+}
+
+// parseOptions returns the parse options from the configuration.
+func parseOptions(cfg *config.Config) *models.ParseOptions {
+	return &models.ParseOptions{
+		ColumnAliases: cfg.ColumnAliases,
+		Lenient:       cfg.Lenient,
+	}
+}
+
+// printDiagnostics prints a summary of the rows that were skipped while
+// parsing to standard error.
+func printDiagnostics(diagnostics []models.Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "warning: skipped %d row(s) that couldn't be parsed\n", len(diagnostics))
+
+	for i, diagnostic := range diagnostics {
+		if i == maxPrintedDiagnostics {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(diagnostics)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  %v\n", diagnostic)
+	}
+}
+
+// updateSource downloads every file of the data source in to the data
+// directory.
+func updateSource(source sources.DataSource, cfg *config.Config, paths *config.Paths) error {
+	fetcher, err := newFetcher(cfg)
+	if err != nil {
+		return err
+	}
+
+	store := snapshots.NewStore(paths.SnapshotDir())
+
+	for _, file := range source.Files() {
+		err := updateDataset(paths.DataPath(file.Name), datasetURL(file, cfg), cfg.Checksums[file.Name], fetcher, store)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newFetcher creates and returns a fetcher with the HTTP settings of the
+// configuration.
+func newFetcher(cfg *config.Config) (*fetch.Fetcher, error) {
+	options := fetch.ClientOptions{
+		Proxy:    cfg.HTTP.Proxy,
+		CAFile:   cfg.HTTP.CAFile,
+		CertFile: cfg.HTTP.CertFile,
+		KeyFile:  cfg.HTTP.KeyFile,
+	}
+
+	timeouts := []struct {
+		name     string
+		value    string
+		duration *time.Duration
+	}{
+		{"connectTimeout", cfg.HTTP.ConnectTimeout, &options.ConnectTimeout},
+		{"responseTimeout", cfg.HTTP.ResponseTimeout, &options.ResponseTimeout},
+		{"timeout", cfg.HTTP.Timeout, &options.Timeout},
+	}
+
+	for _, timeout := range timeouts {
+		if len(timeout.value) == 0 {
+			continue
+		}
+
+		duration, err := time.ParseDuration(timeout.value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a duration such as 30s", timeout.name, timeout.value)
+		}
+		*timeout.duration = duration
+	}
+
+	client, err := fetch.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := fetch.NewFetcher()
+	fetcher.Client = client
+	fetcher.Header = make(http.Header)
+	for name, value := range cfg.HTTP.Headers {
+		fetcher.Header.Set(name, value)
+	}
+
+	return fetcher, nil
+}
+
+// datasetURL returns the URL that the data source's file is downloaded from,
+// which is the file's URL in the configuration, the file in the configured
+// mirror or the data source's URL.
+func datasetURL(file sources.File, cfg *config.Config) string {
+	if url, ok := cfg.URLs[file.Name]; ok && len(url) > 0 {
+		return url
+	}
+
+	if len(cfg.Mirror) > 0 {
+		return strings.TrimSuffix(cfg.Mirror, "/") + "/" + file.Name
+	}
+
+	return file.URL
+}
+
+// cachedSource wraps the data source so that its parsed world is cached in
+// the cache directory.
+func cachedSource(source sources.DataSource, paths *config.Paths) *sources.CachedSource {
+	return sources.NewCachedSource(source, paths.CachePath(source.Name()+".world"))
+}
+
+// updateIfRequested updates the selected data source if the global update
+// flag is set, and returns whether or not it was updated. Nothing is updated
+// when the command's file flag is set.
+func updateIfRequested(c *cli.Context) (bool, error) {
+	if os.Getenv("UPDATE_DATA") != "true" || len(c.String("file")) > 0 {
+		return false, nil
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return false, err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return false, err
+	}
+
+	return true, updateSource(source, cfg, paths)
+}
+
+// cacheDataset parses the data source's files in the data directory so that
+// later commands load the world from the cache, and prints the changes to
+// the dataset. Only new and revised rows are parsed if the cache is out of
+// date. Failing to cache the dataset is only a warning, in which case nil is
+// returned in place of the world.
+func cacheDataset(source sources.DataSource, cfg *config.Config, paths *config.Paths) *models.World {
+	cached := cachedSource(source, paths)
+
+	s := NewSpinnerWithTitle("Caching dataset...")
+	s.Start()
+	world, err := cached.Parse(paths.DataDir, parseOptions(cfg))
+	s.Stop()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to cache the dataset: %v\n", err)
+		return nil
+	}
+
+	if changes := cached.Changes(); changes != nil {
+		printChanges(changes.Diff)
+	}
+
+	return world
+}
+
+// recordCounts records the counts of the world parsed from the data source's
+// files in the files' provenance. Failing to record them is only a warning.
+func recordCounts(source sources.DataSource, paths *config.Paths, world *models.World) {
+	counts := provenance.NewCounts(world)
+
+	for _, file := range source.Files() {
+		path := paths.DataPath(file.Name)
+		metadata, err := provenance.Read(path)
+		if err != nil {
+			continue
+		}
+
+		metadata.Counts = counts
+		if err = provenance.Write(path, metadata); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: unable to record the provenance of %s: %v\n", file.Name, err)
+		}
+	}
+}
+
+// printChanges prints a summary of the changes an update made to the
+// dataset.
+func printChanges(diff *models.WorldDiff) {
+	if diff.Empty() {
+		fmt.Println("No changes to the dataset.")
+		return
+	}
+
+	fmt.Printf("%-20s %d\n", "Added records", diff.AddedRecords)
+	fmt.Printf("%-20s %d\n", "Removed records", diff.RemovedRecords)
+	fmt.Printf("%-20s %d\n", "Revised values", len(diff.Changes))
+	fmt.Printf("%-20s %d\n", "New locations", len(diff.AddedLocations))
+	fmt.Printf("%-20s %d\n", "Removed locations", len(diff.RemovedLocations))
+	fmt.Printf("%-20s %d\n", "Updated locations", len(diff.UpdatedLocations))
+}
+
+// loadWorld updates the dataset if requested and then loads the world with
+// parseWorld.
+func loadWorld(c *cli.Context) (*models.World, error) {
+	updated, err := updateIfRequested(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseWorld(c, updated)
+}
+
+// parseWorld loads the world from the selected data source, or from its
+// snapshots if the global as-of flag is set. If the command's file flag is
+// set, the world is loaded from that file instead. The dataset is expected to
+// be up to date, and if it was just updated the parsed world is cached and
+// its counts are recorded in the dataset's provenance.
+func parseWorld(c *cli.Context, updated bool) (*models.World, error) {
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if file := c.String("file"); len(file) > 0 {
+		if len(c.String("asOf")) > 0 {
+			return nil, errors.New("the file and as-of flags can't be used together")
+		}
+
+		world, err := parseInputFile(source, cfg, paths, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = joinLocationInfo(world, cfg); err != nil {
+			return nil, err
+		}
+
+		printDiagnostics(world.Diagnostics)
+		return world, nil
+	}
+
+	if asOf := c.String("asOf"); len(asOf) > 0 {
+		t, err := parseTime(asOf, true)
+		if err != nil {
+			return nil, err
+		}
+
+		world, err := parseSnapshotsAsOf(source, paths, cfg, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = joinLocationInfo(world, cfg); err != nil {
+			return nil, err
+		}
+
+		printDiagnostics(world.Diagnostics)
+		return world, nil
+	}
+
+	// Get the world locations
+	world, err := cachedSource(source, paths).Parse(paths.DataDir, parseOptions(cfg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no %s dataset found in %s, run \"covid19 --source %s update data\" first", source.Name(), paths.DataDir, source.Name())
+		}
+		return nil, err
+	}
+
+	if updated {
+		recordCounts(source, paths, world)
+	}
+
+	if err = joinLocationInfo(world, cfg); err != nil {
+		return nil, err
+	}
+
+	printDiagnostics(world.Diagnostics)
+	return world, nil
+}
+
+// locationTable returns the built-in location metadata with the rows of the
+// configuration's location metadata file merged over it.
+func locationTable(cfg *config.Config) (*models.LocationTable, error) {
+	table := models.DefaultLocationTable()
+	if len(cfg.LocationMetadata) == 0 {
+		return table, nil
+	}
+
+	overrides, err := models.ReadLocationTableFromPath(cfg.LocationMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the location metadata: %v", err)
+	}
+
+	table.Merge(overrides)
+	return table, nil
+}
+
+// joinLocationInfo joins the location metadata on to the world's locations.
+func joinLocationInfo(world *models.World, cfg *config.Config) error {
+	table, err := locationTable(cfg)
+	if err != nil {
+		return err
+	}
+
+	world.JoinLocationInfo(table)
+	return nil
+}
+
+// locationGroups returns the built-in location groups of the table with the
+// configuration's groups merged over them.
+func locationGroups(cfg *config.Config, table *models.LocationTable) *models.LocationGroups {
+	var names []string
+	for name := range cfg.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := models.DefaultLocationGroups(table)
+	for _, name := range names {
+		groups.Add(&models.LocationGroup{Name: name, Members: cfg.Groups[name]})
+	}
+	return groups
+}
+
+// loadLocations returns the location metadata and groups of the
+// configuration.
+func loadLocations(c *cli.Context) (*models.LocationTable, *models.LocationGroups, error) {
+	cfg, _, err := loadPaths(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	table, err := locationTable(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return table, locationGroups(cfg, table), nil
+}
+
+// locationNames returns the names that a dataset may give the location with
+// the given name: its aliases, and the name and aliases of its metadata.
+func locationNames(table *models.LocationTable, name string) []string {
+	names := models.LocationAliases(name)
+	if info, ok := table.Lookup(name); ok {
+		names = append(names, models.LocationAliases(info.Name)...)
+	}
+	return names
+}
+
+// findLocation returns the world's location with the given name, ISO code,
+// alias or path, or the aggregate of the location group with the name. The
+// name "world" refers to the world's records. An error suggesting similar
+// names is returned if there is no such location.
+//
+// Groups from the configuration take precedence over the world's locations.
+// Built-in groups are only used if the world doesn't have a location with the
+// name, so datasets' own continent series are preferred to the sums of their
+// countries.
+func findLocation(c *cli.Context, world *models.World, name string) (*models.Location, error) {
+	if strings.EqualFold(strings.TrimSpace(name), "world") {
+		l := models.NewLocation("World", world.Records)
+		l.Info = world.Info
+		return l, nil
+	}
+
+	cfg, _, err := loadPaths(c)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := locationTable(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return lookupLocation(world, cfg, locationGroups(cfg, table), name)
+}
+
+// lookupLocation returns the world's location or the aggregate of the group
+// with the given name like findLocation, using the configuration's groups.
+func lookupLocation(world *models.World, cfg *config.Config, groups *models.LocationGroups, name string) (*models.Location, error) {
+	group, isGroup := groups.Lookup(name)
+	if isGroup && isConfiguredGroup(cfg, group.Name) {
+		return aggregateGroup(world, group)
+	}
+
+	if l := world.Location(name); l != nil {
+		return l, nil
+	}
+
+	// Suggest the regions of the path's parent if it has one
+	if names := models.SplitLocationPath(name); len(names) > 1 {
+		if parent := world.Location(strings.Join(names[:len(names)-1], models.LocationPathSeparator)); parent != nil {
+			var candidates []string
+			for _, child := range parent.Children {
+				candidates = append(candidates, child.Path())
+			}
+			return nil, unknownLocationError(name, candidates)
+		}
+	}
+
+	if !isGroup {
+		candidates := world.LocationNames()
+		for _, group := range groups.Groups() {
+			candidates = append(candidates, group.Name)
+		}
+		return nil, unknownLocationError(name, candidates)
+	}
+
+	return aggregateGroup(world, group)
+}
+
+// isConfiguredGroup returns whether or not the group with the given name is
+// defined by the configuration rather than built in.
+func isConfiguredGroup(cfg *config.Config, name string) bool {
+	for configured := range cfg.Groups {
+		if strings.EqualFold(strings.TrimSpace(configured), strings.TrimSpace(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// aggregateGroup returns the sum of the world's locations in the group, or
+// an error if none of them are in the world.
+func aggregateGroup(world *models.World, group *models.LocationGroup) (*models.Location, error) {
+	l := world.Aggregate(group)
+	if l == nil {
+		return nil, fmt.Errorf("none of the locations in the %s group are in the dataset", group.Name)
+	}
+	return l, nil
+}
+
+// unknownLocationError returns the error of a location name that doesn't
+// match any of the candidates, suggesting the closest ones.
+func unknownLocationError(name string, candidates []string) error {
+	suggestions := models.SuggestLocations(name, candidates, 3)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("no location or group named %q, run \"covid19 list data\" for the dataset's locations", name)
+	}
+
+	list := suggestions[0]
+	if len(suggestions) > 1 {
+		list = strings.Join(suggestions[:len(suggestions)-1], ", ") + " or " + suggestions[len(suggestions)-1]
+	}
+	return fmt.Errorf("no location or group named %q, did you mean %s?", name, list)
+}
+
+// openRecords opens a reader of the records selected by filter from the
+// dataset, which is expected to be up to date. Whether or not the records can be read
+// without loading the world is returned by ok, which is false for sources
+// that can't stream their records and when the global as-of flag or the
+// command's file flag is set.
+func openRecords(c *cli.Context, filter *models.RecordFilter) (reader *models.RecordReader, ok bool, err error) {
+	if len(c.String("asOf")) > 0 || len(c.String("file")) > 0 {
+		return nil, false, nil
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return nil, false, err
+	}
+
+	recordSource, ok := source.(sources.RecordSource)
+	if !ok {
+		return nil, false, nil
+	}
+
+	reader, err = recordSource.Records(paths.DataDir, parseOptions(cfg), filter)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, fmt.Errorf("no %s dataset found in %s, run \"covid19 --source %s update data\" first", source.Name(), paths.DataDir, source.Name())
+		}
+		return nil, false, err
+	}
+
+	return reader, true, nil
+}
+
+// parseInputFile parses the CSV, gzip or zip file at path, or standard input if
+// path is "-", with the data source. The file replaces the data source's
+// files that it contains and the others are read from the data directory.
+func parseInputFile(source sources.DataSource, cfg *config.Config, paths *config.Paths, path string) (*models.World, error) {
+	dir, err := ioutil.TempDir("", "covid19-file-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	inputs, err := extractInput(path, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", path, err)
+	}
+
+	world, _, err := parseInputs(source, cfg, paths, inputs, "")
+	return world, err
+}
+
+// loadProvenance returns the provenance of the files that loadWorld loads the
+// world from, or nil if it isn't known. The provenance of snapshots is their
+// hash and the time they were taken.
+func loadProvenance(c *cli.Context) ([]*provenance.Metadata, error) {
+	if len(c.String("file")) > 0 {
+		return nil, nil
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var asOf time.Time
+	if value := c.String("asOf"); len(value) > 0 {
+		if asOf, err = parseTime(value, true); err != nil {
+			return nil, err
+		}
+	}
+
+	store := snapshots.NewStore(paths.SnapshotDir())
+
+	var files []*provenance.Metadata
+	for _, file := range source.Files() {
+		if !asOf.IsZero() {
+			snapshot, err := store.AsOf(file.Name, asOf)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, &provenance.Metadata{
+				File:      file.Name,
+				FetchedAt: snapshot.Time,
+				SHA256:    snapshot.Hash,
+				Snapshot:  snapshot.ID(),
+			})
+			continue
+		}
+
+		metadata, err := provenance.Read(paths.DataPath(file.Name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		metadata.File = file.Name
+		files = append(files, metadata)
+	}
+
+	return files, nil
+}
+
+// parseSnapshotsAsOf parses the snapshots of the data source's files that
+// were current at time t.
+func parseSnapshotsAsOf(source sources.DataSource, paths *config.Paths, cfg *config.Config, t time.Time) (*models.World, error) {
+	files := source.Files()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("the %s data source has no snapshots", source.Name())
+	}
+
+	dir, err := ioutil.TempDir("", "covid19-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	store := snapshots.NewStore(paths.SnapshotDir())
+	for _, file := range files {
+		snapshot, err := store.AsOf(file.Name, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = snapshot.Extract(filepath.Join(dir, file.Name)); err != nil {
+			return nil, err
+		}
+	}
+
+	return source.Parse(dir, parseOptions(cfg))
+}
+
+// parseTime parses a date (2006-01-02), which is taken as the start or end of
+// that day in UTC, or an RFC 3339 timestamp.
+func parseTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			return t.Add(24*time.Hour - time.Second), nil
+		}
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD or an RFC 3339 timestamp", value)
+	}
+
+	return t, nil
+}
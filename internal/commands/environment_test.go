@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/config"
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestLookupLocation(t *testing.T) {
+	date := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	location := func(name string, cases int) *models.Location {
+		return models.NewLocation(name, []*models.COVRecord{{Date: date, Location: name, TotalCases: cases}})
+	}
+
+	world := models.NewWorld([]*models.Location{
+		location("Europe", 1000),
+		location("France", 10),
+		location("Italy", 20),
+		location("Japan", 30),
+	}, nil)
+
+	cfg := &config.Config{Groups: map[string][]string{
+		"europe":     {"France", "Italy"},
+		"Our Market": {"Italy", "Japan"},
+	}}
+
+	groups := models.NewLocationGroups([]*models.LocationGroup{
+		{Name: "Asia", Members: []string{"Japan"}},
+		{Name: "Europe", Members: []string{"France", "Italy", "Spain"}},
+	})
+	for name, members := range cfg.Groups {
+		groups.Add(&models.LocationGroup{Name: name, Members: members})
+	}
+
+	tests := []struct {
+		name  string
+		cases int
+		err   string
+	}{
+		{"Italy", 20, ""},
+		{"Europe", 30, ""},
+		{"our market", 50, ""},
+		{"Asia", 30, ""},
+		{"Itly", 0, "did you mean Italy?"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := lookupLocation(world, cfg, groups, test.name)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected an error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cases := l.TotalCases(); cases != test.cases {
+				t.Errorf("expected %d total cases, got %d", test.cases, cases)
+			}
+		})
+	}
+
+	// Without a configured group, the dataset's location takes precedence
+	// over the built-in group
+	l, err := lookupLocation(world, &config.Config{}, groups, "Europe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cases := l.TotalCases(); cases != 1000 {
+		t.Errorf("expected the dataset's Europe, got %d total cases", cases)
+	}
+}
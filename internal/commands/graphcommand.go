@@ -4,7 +4,6 @@ package commands
 import (
 	"fmt"
 	"math"
-	"os"
 	"strings"
 
 	"github.com/colinc86/covid-19/internal/models"
@@ -19,8 +18,9 @@ type GraphCommandHandler struct {
 	Description string
 
 	// MARK: Private properties
-	location string
-	graph    string
+	location  string
+	graph     string
+	perCapita bool
 }
 
 // MARK: Initializers
@@ -38,7 +38,16 @@ func NewGraphCommandHandler() *GraphCommandHandler {
 			covid19 graph data
 			
 			# Graph the data for a specific location
-			covid19 graph data -l [location]`,
+			covid19 graph data -l [location]
+			
+			# Graph a different value
+			covid19 graph data -v icuPatients
+			
+			# Graph the sum of a group of locations
+			covid19 graph data -l "European Union"
+			
+			# Graph a location's deaths per million people
+			covid19 graph data -l [location] -v totalDeaths --perCapita`,
 	}
 }
 
@@ -61,17 +70,30 @@ func (h *GraphCommandHandler) Command() *cli.Command {
 					&cli.StringFlag{
 						Name:        "location",
 						Aliases:     []string{"l"},
-						Usage:       "Filter by location.",
+						Usage:       "Filter by location or location group.",
 						Required:    false,
 						Destination: &h.location,
 					},
 					&cli.StringFlag{
 						Name:        "value",
 						Aliases:     []string{"v"},
-						Usage:       "Value by newCases, newDeaths, totalCases or totalDeaths.",
+						Usage:       "Value by " + metricUsage + ".",
 						Required:    false,
 						Destination: &h.graph,
 					},
+					&cli.BoolFlag{
+						Name:        "perCapita",
+						Aliases:     []string{"pc"},
+						Usage:       "Graph values per million people.",
+						Required:    false,
+						Destination: &h.perCapita,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "Load the dataset from a CSV, gzip or zip file, or - for standard input.",
+						Required: false,
+					},
 				},
 			},
 		},
@@ -80,16 +102,8 @@ func (h *GraphCommandHandler) Command() *cli.Command {
 
 // GraphDataSetAction graphs the full dataset.
 func (h *GraphCommandHandler) GraphDataSetAction(c *cli.Context) error {
-	if os.Getenv("UPDATE_DATA") == "true" {
-		// Update our data set
-		err := updateDataset(localPath, dataSetURL)
-		if err != nil {
-			return err
-		}
-	}
-
 	// Get the world locations
-	world, err := models.NewWorldFromPath(localPath)
+	world, err := loadWorld(c)
 	if err != nil {
 		return err
 	}
@@ -98,107 +112,78 @@ func (h *GraphCommandHandler) GraphDataSetAction(c *cli.Context) error {
 		h.graph = "totalCases"
 	}
 
-	// Get the total value for the location in question
-	total := 0
-	if len(h.location) == 0 || strings.ToLower(h.location) == "world" {
-		switch strings.ToLower(h.graph) {
-		case "newcases", "newdeaths":
-			total = 0
-		case "totalcases":
-			total = world.TotalCases()
-		case "totaldeaths":
-			total = world.TotalDeaths()
+	metric, err := models.ParseMetric(h.graph)
+	if err != nil {
+		return err
+	}
+
+	// Get the records for the location in question
+	records := world.Records
+	population := world.Population()
+	if len(h.location) > 0 && strings.ToLower(h.location) != "world" {
+		l, err := findLocation(c, world, h.location)
+		if err != nil {
+			return err
 		}
-	} else if len(h.location) > 0 {
-		for _, l := range world.Locations {
-			if strings.ToLower(l.Name) != strings.ToLower(h.location) {
-				continue
-			}
 
-			switch strings.ToLower(h.graph) {
-			case "newcases":
-				total = l.NewCases()
-			case "newdeaths":
-				total = l.NewDeaths()
-			case "totalcases":
-				total = l.TotalCases()
-			case "totaldeaths":
-				total = l.TotalDeaths()
-			}
+		records = l.Records
+		population = l.Population()
+	}
 
-			break
-		}
+	if h.perCapita && population <= 0 && len(records) > 0 {
+		return fmt.Errorf("the population of %s isn't known", records[0].Location)
 	}
 
-	// Get the header value name
-	name := ""
-	switch strings.ToLower(h.graph) {
-	case "newcases":
-		name = "New Cases"
-	case "newdeaths":
-		name = "New Deaths"
-	case "totalcases":
-		name = "Total Cases"
-	case "totaldeaths":
-		name = "Total Deaths"
+	// Get the largest value to scale the bars by
+	total := 0.0
+	for _, r := range records {
+		if value, ok := h.value(r, metric, population); ok && value > total {
+			total = value
+		}
 	}
 
-	fmt.Printf("%-32s %-12s\n", "Date", name)
+	title := metric.Title()
+	if h.perCapita {
+		title += "/M"
+	}
+	fmt.Printf("%-32s %-12s\n", "Date", title)
 
 	// Draw the graphs
-	if len(h.location) == 0 || strings.ToLower(h.location) == "world" {
-		for _, r := range world.Records {
-			value := 0
-			switch strings.ToLower(h.graph) {
-			case "newcases":
-				value = r.NewCases
-			case "newdeaths":
-				value = r.NewDeaths
-			case "totalcases":
-				value = r.TotalCases
-			case "totaldeaths":
-				value = r.TotalDeaths
-			}
+	for _, r := range records {
+		value, ok := h.value(r, metric, population)
+		if !ok {
+			fmt.Printf("%-32v %-12s %s\n", r.Date, "n/a", "?")
+			continue
+		}
 
-			bar := ""
-			ticks := int(math.Ceil(float64(value) / (float64(total) / 40.0)))
+		bar := ""
+		if total > 0 {
+			ticks := int(math.Ceil(value / (total / 40.0)))
 			for i := 0; i < ticks; i++ {
 				bar += "#"
 			}
-
-			fmt.Printf("%-32v %-12d %s\n", r.Date, value, bar)
 		}
-	} else if len(h.location) > 0 {
-		for _, l := range world.Locations {
-			if strings.ToLower(l.Name) != strings.ToLower(h.location) {
-				continue
-			}
-
-			for _, r := range l.Records {
-				value := 0
-				switch strings.ToLower(h.graph) {
-				case "newcases":
-					value = r.NewCases
-				case "newdeaths":
-					value = r.NewDeaths
-				case "totalcases":
-					value = r.TotalCases
-				case "totaldeaths":
-					value = r.TotalDeaths
-				}
-
-				bar := ""
-				ticks := int(math.Ceil(float64(value) / (float64(total) / 40.0)))
-				for i := 0; i < ticks; i++ {
-					bar += "#"
-				}
-
-				fmt.Printf("%-32v %-12d %s\n", r.Date, value, bar)
-			}
 
-			break
+		if h.perCapita {
+			fmt.Printf("%-32v %-12.2f %s\n", r.Date, value, bar)
+		} else {
+			fmt.Printf("%-32v %-12d %s\n", r.Date, int(value), bar)
 		}
 	}
 
 	return nil
 }
+
+// MARK: Unexported methods
+
+// value returns the record's value of the metric, per million people of the
+// population if the per capita flag is set, and whether or not it was
+// reported.
+func (h *GraphCommandHandler) value(r *models.COVRecord, metric models.Metric, population int) (float64, bool) {
+	if h.perCapita {
+		return r.PerMillion(metric, population)
+	}
+
+	value, ok := r.Lookup(metric)
+	return float64(value), ok
+}
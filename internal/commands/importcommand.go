@@ -0,0 +1,204 @@
+// Package commands conatins the commands for the medina command line application.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/snapshots"
+	"github.com/urfave/cli/v2"
+)
+
+// ImportCommandHandler handles import commands.
+type ImportCommandHandler struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+
+	// MARK: Private properties
+	name string
+}
+
+// MARK: Initializers
+
+// NewImportCommandHandler creates and returns a new import command handler.
+func NewImportCommandHandler() *ImportCommandHandler {
+	return &ImportCommandHandler{
+		Name:    "import",
+		Aliases: []string{"im"},
+		Usage:   "Imports a dataset from a file.",
+		Description: `Install a CSV dataset from a file or standard input in place of downloading
+		it. Files may be plain, gzip compressed or zip archives of CSV files, and
+		are parsed before they replace the data source's files.
+		
+		Examples:
+			# Import a dataset
+			covid19 import owid-covid-data.csv
+			
+			# Import a compressed dataset from standard input
+			curl -s [url] | covid19 import -
+			
+			# Import the files of a multiple file data source from a zip archive
+			covid19 --source jhu import jhu.zip
+			
+			# Import a file under the name of one of the data source's files
+			covid19 --source jhu import --name time_series_covid19_deaths_global.csv deaths.csv`,
+	}
+}
+
+// MARK: Public methods
+
+// Command creates and returns the handler's command.
+func (h *ImportCommandHandler) Command() *cli.Command {
+	return &cli.Command{
+		Name:        h.Name,
+		Aliases:     h.Aliases,
+		Usage:       h.Usage,
+		Description: h.Description,
+		ArgsUsage:   "[file|-]...",
+		Action:      h.ImportAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "name",
+				Aliases:     []string{"n"},
+				Usage:       "The name of the data source's file that a single input replaces.",
+				Required:    false,
+				Destination: &h.name,
+			},
+		},
+	}
+}
+
+// ImportAction parses the given files and installs them in the data
+// directory.
+func (h *ImportCommandHandler) ImportAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("expected a file to import, or - for standard input")
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(source.Files()) == 0 {
+		return fmt.Errorf("the %s data source reads its file in place and can't be imported to", source.Name())
+	}
+
+	if err = paths.EnsureDataDir(); err != nil {
+		return err
+	}
+
+	// Decompress the inputs next to the data directory's files so that they
+	// can be moved in to place
+	dir, err := ioutil.TempDir(paths.DataDir, ".import-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Note where each input came from for its provenance
+	var inputs []inputFile
+	origins := make(map[string]string)
+	for _, arg := range c.Args().Slice() {
+		files, err := extractInput(arg, dir)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %v", arg, err)
+		}
+		inputs = append(inputs, files...)
+
+		origin := "stdin"
+		if arg != "-" {
+			if origin, err = filepath.Abs(arg); err != nil {
+				return err
+			}
+		}
+
+		for _, file := range files {
+			origins[file.path] = origin
+		}
+	}
+
+	world, assigned, err := parseInputs(source, cfg, paths, inputs, h.name)
+	if err != nil {
+		return fmt.Errorf("not a valid %s dataset: %v", source.Name(), err)
+	}
+
+	printDiagnostics(world.Diagnostics)
+
+	// Verify the files with pinned checksums before installing any of them
+	for name, inputPath := range assigned {
+		if checksum := cfg.Checksums[name]; len(checksum) > 0 {
+			if err = provenance.VerifyChecksum(name, inputPath, checksum); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Install the files in place of the data source's files
+	store := snapshots.NewStore(paths.SnapshotDir())
+	counts := provenance.NewCounts(world)
+	for _, file := range source.Files() {
+		inputPath, ok := assigned[file.Name]
+		if !ok {
+			continue
+		}
+
+		path := paths.DataPath(file.Name)
+		if err = os.Rename(inputPath, path); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		snapshot, _, err := store.Add(file.Name, path, now)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		// The metadata replaces the HTTP validators of any previous download,
+		// so the next update downloads the file in full
+		metadata := &provenance.Metadata{
+			File:      file.Name,
+			URL:       origins[inputPath],
+			FetchedAt: now,
+			CheckedAt: now,
+			SHA256:    snapshot.Hash,
+			Size:      info.Size(),
+			Snapshot:  snapshot.ID(),
+			Counts:    counts,
+		}
+
+		if err = provenance.Write(path, metadata); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %s\n", file.Name)
+	}
+
+	fmt.Printf("%-16s %d\n", "Locations", counts.Locations)
+	fmt.Printf("%-16s %d\n", "Records", counts.Rows)
+	if len(counts.FirstDate) > 0 {
+		fmt.Printf("%-16s %s\n", "First date", counts.FirstDate)
+		fmt.Printf("%-16s %s\n", "Last date", counts.LastDate)
+	}
+
+	// Parse the new data set so that later commands load it from the cache
+	cacheDataset(source, cfg, paths)
+	return nil
+}
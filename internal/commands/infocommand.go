@@ -0,0 +1,342 @@
+// Package commands conatins the commands for the medina command line application.
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/sources"
+	"github.com/urfave/cli/v2"
+)
+
+// InfoCommandHandler handles info commands.
+type InfoCommandHandler struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+
+	// MARK: Private properties
+	format string
+	verify bool
+}
+
+// fileProvenance types contain the provenance of one of a data source's
+// files and the result of verifying it.
+type fileProvenance struct {
+	*provenance.Metadata
+
+	// The path of the file.
+	Path string `json:"path"`
+
+	// The checksum the file is pinned to in the configuration.
+	PinnedSHA256 string `json:"pinnedSha256,omitempty"`
+
+	// Whether or not the file passed verification, or nil if it wasn't
+	// verified.
+	Verified *bool `json:"verified,omitempty"`
+
+	// The reasons the file failed verification.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// MARK: Initializers
+
+// NewInfoCommandHandler creates and returns a new info command handler.
+func NewInfoCommandHandler() *InfoCommandHandler {
+	return &InfoCommandHandler{
+		Name:    "info",
+		Aliases: []string{"i"},
+		Usage:   "Shows information about the application.",
+		Description: `Show information about the application's environment.
+		
+		Examples:
+			# Show the resolved file paths
+			covid19 info paths
+			
+			# Show the paths for a different data directory
+			covid19 --dataDir [directory] info paths
+			
+			# Show the available data sources
+			covid19 info sources
+			
+			# Show the location groups
+			covid19 info groups
+			
+			# Show where the dataset came from
+			covid19 info data
+			
+			# Check that the dataset is unchanged since it was fetched and
+			# matches its pinned checksum
+			covid19 info data --verify`,
+	}
+}
+
+// MARK: Public methods
+
+// Command creates and returns the handler's command.
+func (h *InfoCommandHandler) Command() *cli.Command {
+	return &cli.Command{
+		Name:        h.Name,
+		Aliases:     h.Aliases,
+		Usage:       h.Usage,
+		Description: h.Description,
+		Subcommands: []*cli.Command{
+			&cli.Command{
+				Name:    "paths",
+				Aliases: []string{"p"},
+				Action:  h.InfoPathsAction,
+				Usage:   "The resolved file paths.",
+			},
+			&cli.Command{
+				Name:    "sources",
+				Aliases: []string{"s"},
+				Action:  h.InfoSourcesAction,
+				Usage:   "The available data sources.",
+			},
+			&cli.Command{
+				Name:    "groups",
+				Aliases: []string{"g"},
+				Action:  h.InfoGroupsAction,
+				Usage:   "The location groups.",
+			},
+			&cli.Command{
+				Name:    "data",
+				Aliases: []string{"d"},
+				Action:  h.InfoDataAction,
+				Usage:   "The provenance of the dataset.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:        "verify",
+						Usage:       "Check the files' hashes against their provenance and pinned checksums.",
+						Required:    false,
+						Destination: &h.verify,
+					},
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "The output format, table or json.",
+						Required:    false,
+						Value:       "table",
+						Destination: &h.format,
+					},
+				},
+			},
+		},
+	}
+}
+
+// InfoPathsAction prints the resolved file paths.
+func (h *InfoCommandHandler) InfoPathsAction(c *cli.Context) error {
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-16s %-64s %s\n", "Name", "Path", "Source")
+	fmt.Printf("%-16s %-64s %s\n", "Config file", paths.ConfigFile, "")
+	fmt.Printf("%-16s %-64s %s\n", "Data directory", paths.DataDir, paths.DataDirSource)
+	for _, file := range source.Files() {
+		fmt.Printf("%-16s %-64s %s\n", "Data file", paths.DataPath(file.Name), source.Name())
+	}
+	fmt.Printf("%-16s %-64s %s\n", "Cache directory", paths.CacheDir, paths.CacheDirSource)
+
+	return nil
+}
+
+// InfoSourcesAction prints the available data sources.
+func (h *InfoCommandHandler) InfoSourcesAction(c *cli.Context) error {
+	fmt.Printf("%-16s %s\n", "Name", "Description")
+
+	for _, name := range sources.Names() {
+		fmt.Printf("%-16s %s\n", name, sources.Describe(name))
+	}
+
+	return nil
+}
+
+// InfoGroupsAction prints the built-in and configured location groups.
+func (h *InfoCommandHandler) InfoGroupsAction(c *cli.Context) error {
+	_, groups, err := loadLocations(c)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-16s %s\n", "Name", "Locations")
+
+	for _, group := range groups.Groups() {
+		fmt.Printf("%-16s %s\n", group.Name, strings.Join(group.Members, ", "))
+	}
+
+	return nil
+}
+
+// InfoDataAction prints the provenance of the data source's files.
+func (h *InfoCommandHandler) InfoDataAction(c *cli.Context) error {
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(source.Files()) == 0 {
+		return fmt.Errorf("the %s data source reads its file in place and has no provenance", source.Name())
+	}
+
+	var files []*fileProvenance
+	failed := false
+
+	for _, file := range source.Files() {
+		path := paths.DataPath(file.Name)
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("no %s dataset found in %s, run \"covid19 --source %s update data\" first", source.Name(), paths.DataDir, source.Name())
+		}
+
+		// Files fetched before provenance was recorded only have validators
+		metadata, err := provenance.Read(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if metadata == nil {
+			metadata = &provenance.Metadata{}
+		}
+		metadata.File = file.Name
+
+		info := &fileProvenance{
+			Metadata:     metadata,
+			Path:         path,
+			PinnedSHA256: strings.ToLower(cfg.Checksums[file.Name]),
+		}
+
+		if h.verify {
+			if err = h.verifyFile(info); err != nil {
+				return err
+			}
+			failed = failed || !*info.Verified
+		}
+
+		files = append(files, info)
+	}
+
+	if h.format == "json" {
+		data, err := json.MarshalIndent(files, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for i, info := range files {
+			if i > 0 {
+				fmt.Println()
+			}
+			h.printProvenance(info)
+		}
+	}
+
+	if failed {
+		return cli.Exit("the dataset failed verification", 1)
+	}
+
+	return nil
+}
+
+// MARK: Unexported methods
+
+// verifyFile hashes the file and compares the hash with the file's
+// provenance and pinned checksum.
+func (h *InfoCommandHandler) verifyFile(info *fileProvenance) error {
+	hash, err := provenance.HashFile(info.Path)
+	if err != nil {
+		return err
+	}
+
+	if len(info.SHA256) == 0 {
+		info.Problems = append(info.Problems, "no checksum was recorded when the file was fetched")
+	} else if hash != info.SHA256 {
+		info.Problems = append(info.Problems, fmt.Sprintf("the file was modified after it was fetched, its checksum is %s", hash))
+	}
+
+	if len(info.PinnedSHA256) > 0 && hash != info.PinnedSHA256 {
+		info.Problems = append(info.Problems, fmt.Sprintf("the file's checksum %s doesn't match the pinned checksum", hash))
+	}
+
+	verified := len(info.Problems) == 0
+	info.Verified = &verified
+	return nil
+}
+
+// printProvenance prints the provenance of a file as a table.
+func (h *InfoCommandHandler) printProvenance(info *fileProvenance) {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "unknown"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	formatString := func(value string) string {
+		if len(value) == 0 {
+			return "unknown"
+		}
+		return value
+	}
+
+	fmt.Printf("%-16s %s\n", "File", info.File)
+	fmt.Printf("%-16s %s\n", "Path", info.Path)
+	fmt.Printf("%-16s %s\n", "URL", formatString(info.URL))
+	fmt.Printf("%-16s %s\n", "Fetched", formatTime(info.FetchedAt))
+	fmt.Printf("%-16s %s\n", "Checked", formatTime(info.CheckedAt))
+
+	if len(info.Validators.ETag) > 0 {
+		fmt.Printf("%-16s %s\n", "ETag", info.Validators.ETag)
+	}
+
+	if len(info.Validators.LastModified) > 0 {
+		fmt.Printf("%-16s %s\n", "Last-Modified", info.Validators.LastModified)
+	}
+
+	fmt.Printf("%-16s %s\n", "SHA-256", formatString(info.SHA256))
+	if len(info.PinnedSHA256) > 0 {
+		fmt.Printf("%-16s %s\n", "Pinned SHA-256", info.PinnedSHA256)
+	}
+
+	fmt.Printf("%-16s %d\n", "Size", info.Size)
+	fmt.Printf("%-16s %s\n", "Snapshot", formatString(info.Snapshot))
+
+	if counts := info.Counts; counts != nil {
+		fmt.Printf("%-16s %d\n", "Rows", counts.Rows)
+		fmt.Printf("%-16s %d\n", "Locations", counts.Locations)
+		fmt.Printf("%-16s %d\n", "Dates", counts.Dates)
+		if len(counts.FirstDate) > 0 {
+			fmt.Printf("%-16s %s\n", "First date", counts.FirstDate)
+			fmt.Printf("%-16s %s\n", "Last date", counts.LastDate)
+		}
+	}
+
+	if info.Verified != nil {
+		if *info.Verified {
+			fmt.Printf("%-16s %s\n", "Verified", "yes")
+		} else {
+			fmt.Printf("%-16s %s\n", "Verified", "no")
+			for _, problem := range info.Problems {
+				fmt.Printf("%-16s %s\n", "", problem)
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/colinc86/covid-19/internal/config"
+	"github.com/colinc86/covid-19/internal/models"
+	"github.com/colinc86/covid-19/internal/sources"
+)
+
+// inputFile types are dataset files read from an input.
+type inputFile struct {
+
+	// The file's name in the input, if it has one.
+	name string
+
+	// The path of the decompressed file.
+	path string
+}
+
+// The magic numbers that identify compressed inputs.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// MARK: Unexported functions
+
+// extractInput decompresses the CSV, gzip or zip file at path, or standard
+// input if path is "-", in to dir and returns the CSV files it contains.
+func extractInput(inputPath string, dir string) ([]inputFile, error) {
+	var reader io.Reader = os.Stdin
+	name := ""
+
+	if inputPath != "-" {
+		file, err := os.Open(inputPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		reader = file
+		name = filepath.Base(inputPath)
+	}
+
+	buffered := bufio.NewReader(reader)
+	magic, _ := buffered.Peek(len(zipMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if len(name) == 0 {
+			name = gzipReader.Name
+		}
+
+		file, err := writeInput(dir, name, gzipReader)
+		if err != nil {
+			return nil, err
+		}
+		return []inputFile{file}, nil
+
+	case bytes.HasPrefix(magic, zipMagic):
+		return extractZip(buffered, dir)
+
+	default:
+		file, err := writeInput(dir, name, buffered)
+		if err != nil {
+			return nil, err
+		}
+		return []inputFile{file}, nil
+	}
+}
+
+// extractZip extracts the CSV files of the zip archive read by reader in to
+// dir.
+func extractZip(reader io.Reader, dir string) ([]inputFile, error) {
+	// Zip archives are read from their end, so they are copied to a file first
+	archive, err := writeInput(dir, "", reader)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.path)
+
+	zipReader, err := zip.OpenReader(archive.path)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	var files []inputFile
+	for _, entry := range zipReader.File {
+		if entry.FileInfo().IsDir() || strings.ToLower(path.Ext(entry.Name)) != ".csv" {
+			continue
+		}
+
+		entryReader, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := writeInput(dir, path.Base(entry.Name), entryReader)
+		entryReader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("the zip archive doesn't contain any CSV files")
+	}
+
+	return files, nil
+}
+
+// writeInput writes the contents of reader to a new file in dir.
+func writeInput(dir string, name string, reader io.Reader) (inputFile, error) {
+	out, err := ioutil.TempFile(dir, "input-")
+	if err != nil {
+		return inputFile{}, err
+	}
+
+	// Temporary files are only readable by their owner, but inputs may be
+	// installed as datasets
+	if err = out.Chmod(0644); err == nil {
+		_, err = io.Copy(out, reader)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(out.Name())
+		return inputFile{}, err
+	}
+
+	return inputFile{name: name, path: out.Name()}, nil
+}
+
+// assignInputs returns the paths of the input files keyed by the names of
+// the data source's files they replace. Inputs are assigned to the file with
+// the same name, or to the only file of single file sources. A non-empty name
+// overrides the name of a single input.
+func assignInputs(source sources.DataSource, inputs []inputFile, name string) (map[string]string, error) {
+	files := source.Files()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("the %s data source doesn't have any files to replace", source.Name())
+	}
+
+	if len(name) > 0 {
+		if len(inputs) != 1 {
+			return nil, fmt.Errorf("a name can only be given to a single file but the input contains %d", len(inputs))
+		}
+		inputs[0].name = name
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+
+	assigned := make(map[string]string)
+	for _, input := range inputs {
+		target := ""
+		for _, file := range files {
+			if file.Name == input.name {
+				target = file.Name
+			}
+		}
+
+		if len(target) == 0 && len(files) == 1 && len(inputs) == 1 {
+			target = files[0].Name
+		}
+
+		if len(target) == 0 {
+			displayName := input.name
+			if len(displayName) == 0 {
+				displayName = "standard input"
+			}
+			return nil, fmt.Errorf("unable to tell which file of the %s data source %s is, expected one of %s", source.Name(), displayName, strings.Join(names, ", "))
+		}
+
+		if _, ok := assigned[target]; ok {
+			return nil, fmt.Errorf("more than one input file for %s", target)
+		}
+		assigned[target] = input.path
+	}
+
+	return assigned, nil
+}
+
+// parseInputs parses the input files with the data source. The inputs replace
+// the files they are assigned to and the data source's other files are read
+// from the data directory.
+func parseInputs(source sources.DataSource, cfg *config.Config, paths *config.Paths, inputs []inputFile, name string) (*models.World, map[string]string, error) {
+	// Sources without files read a CSV file in the default layout
+	if len(source.Files()) == 0 {
+		if len(inputs) != 1 {
+			return nil, nil, fmt.Errorf("expected a single CSV file but the input contains %d", len(inputs))
+		}
+
+		world, err := models.NewWorldFromPathWithOptions(inputs[0].path, parseOptions(cfg))
+		return world, nil, err
+	}
+
+	assigned, err := assignInputs(source, inputs, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "covid19-input-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, file := range source.Files() {
+		filePath, ok := assigned[file.Name]
+		if !ok {
+			filePath = paths.DataPath(file.Name)
+			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+				return nil, nil, fmt.Errorf("the %s data source also needs %s, which isn't in the input or the data directory", source.Name(), file.Name)
+			}
+		}
+
+		absolutePath, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err = os.Symlink(absolutePath, filepath.Join(dir, file.Name)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	world, err := source.Parse(dir, parseOptions(cfg))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return world, assigned, nil
+}
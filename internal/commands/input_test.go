@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/sources"
+)
+
+func TestExtractInput(t *testing.T) {
+	const data = "date,location\n2020-03-01,Italy\n"
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	gzipWriter.Name = "original.csv"
+	gzipWriter.Write([]byte(data))
+	gzipWriter.Close()
+
+	tests := []struct {
+		name     string
+		file     string
+		contents []byte
+		names    []string
+		err      bool
+	}{
+		{"csv", "data.csv", []byte(data), []string{"data.csv"}, false},
+		{"gzip", "data.csv.gz", gzipped.Bytes(), []string{"data.csv"}, false},
+		{"zip", "data.zip", testZip(t, "a.csv", "docs/readme.txt", "nested/b.CSV"), []string{"a.csv", "b.CSV"}, false},
+		{"zip without csv files", "data.zip", testZip(t, "readme.txt"), nil, true},
+		{"missing", "", nil, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "input")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			inputPath := filepath.Join(dir, "missing.csv")
+			if test.contents != nil {
+				inputPath = filepath.Join(dir, test.file)
+				if err = ioutil.WriteFile(inputPath, test.contents, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			files, err := extractInput(inputPath, dir)
+			if test.err {
+				if err == nil {
+					t.Errorf("extractInput = %v, want an error", files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, file.name)
+
+				contents, err := ioutil.ReadFile(file.path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(contents) != data {
+					t.Errorf("%s contents = %q, want %q", file.name, contents, data)
+				}
+			}
+
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, test.names) {
+				t.Errorf("names = %v, want %v", names, test.names)
+			}
+		})
+	}
+}
+
+func TestExtractStandardInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Standard input doesn't have a name, so gzip inputs are named by their
+	// headers
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	gzipWriter.Name = "original.csv"
+	gzipWriter.Write([]byte("date,location\n"))
+	gzipWriter.Close()
+
+	stdinPath := filepath.Join(dir, "stdin")
+	if err = ioutil.WriteFile(stdinPath, gzipped.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdin, err := os.Open(stdinPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+
+	defer func(stdin *os.File) { os.Stdin = stdin }(os.Stdin)
+	os.Stdin = stdin
+
+	files, err := extractInput("-", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].name != "original.csv" {
+		t.Errorf("files = %v, want original.csv", files)
+	}
+}
+
+func TestAssignInputs(t *testing.T) {
+	owid, _ := sources.New("owid")
+	jhu, _ := sources.New("jhu")
+
+	confirmed := "time_series_covid19_confirmed_global.csv"
+	deaths := "time_series_covid19_deaths_global.csv"
+
+	tests := []struct {
+		name   string
+		source sources.DataSource
+		inputs []inputFile
+		rename string
+		want   map[string]string
+	}{
+		{"single file", owid, []inputFile{{"data.csv", "a"}}, "", map[string]string{owid.Files()[0].Name: "a"}},
+		{"standard input", owid, []inputFile{{"", "a"}}, "", map[string]string{owid.Files()[0].Name: "a"}},
+		{"same names", jhu, []inputFile{{deaths, "a"}, {confirmed, "b"}}, "", map[string]string{deaths: "a", confirmed: "b"}},
+		{"renamed", jhu, []inputFile{{"deaths.csv", "a"}}, deaths, map[string]string{deaths: "a"}},
+		{"unknown name", jhu, []inputFile{{"deaths.csv", "a"}}, "", nil},
+		{"duplicate names", jhu, []inputFile{{deaths, "a"}, {deaths, "b"}}, "", nil},
+		{"renamed files", jhu, []inputFile{{deaths, "a"}, {confirmed, "b"}}, deaths, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assigned, err := assignInputs(test.source, test.inputs, test.rename)
+			if test.want == nil {
+				if err == nil {
+					t.Errorf("assignInputs = %v, want an error", assigned)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(assigned, test.want) {
+				t.Errorf("assignInputs = %v, want %v", assigned, test.want)
+			}
+		})
+	}
+}
+
+// testZip returns a zip archive of files with the given names. Each file
+// contains a dataset with a single row.
+func testZip(t *testing.T, names ...string) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	for _, name := range names {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte("date,location\n2020-03-01,Italy\n"))
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive.Bytes()
+}
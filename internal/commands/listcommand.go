@@ -2,11 +2,16 @@
 package commands
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/colinc86/covid-19/internal/models"
+	"github.com/colinc86/covid-19/internal/provenance"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,6 +27,63 @@ type ListCommandHandler struct {
 	world     bool
 	sortBy    string
 	sortOrder string
+	metrics   string
+	from      string
+	to        string
+	format    string
+
+	perCapita     bool
+	minPopulation int
+	regions       bool
+
+	value   string
+	missing string
+	align   string
+}
+
+// seriesListing types contain the aligned series of locations and the
+// provenance of the dataset that they were taken from.
+type seriesListing struct {
+
+	// The provenance of the dataset's files.
+	Dataset []*provenance.Metadata `json:"dataset"`
+
+	// The listed metric.
+	Metric models.Metric `json:"metric"`
+
+	// The policy that gaps were filled with, and the alignment of the series.
+	Missing string `json:"missing"`
+	Align   string `json:"align"`
+
+	// The dates of the common calendar.
+	Dates []string `json:"dates"`
+
+	// The series of each location.
+	Series []*seriesValues `json:"series"`
+}
+
+// seriesValues types contain a location's values on each date of a series
+// listing.
+type seriesValues struct {
+
+	// The name of the location.
+	Location string `json:"location"`
+
+	// The values of the dates, or nil for dates without a value.
+	Values []*float64 `json:"values"`
+
+	// Whether or not the value of each date was reported, rather than filled.
+	Reported []bool `json:"reported"`
+}
+
+// listPrinter types print the rows of a listing as a table, or as a JSON
+// object of the dataset's provenance and the rows. Rows are printed as they
+// are added so that long listings aren't held in memory.
+type listPrinter struct {
+	metrics   []models.Metric
+	json      bool
+	perCapita bool
+	rows      int
 }
 
 // MARK: Initializers
@@ -48,7 +110,34 @@ func NewListCommandHandler() *ListCommandHandler {
 			covid19 list data --sortBy totalCases
 			
 			# Sort by name ascending
-			covid19 list data --sortOrder asc`,
+			covid19 list data --sortOrder asc
+			
+			# List testing and hospital metrics
+			covid19 list data -m totalTests,hospPatients,icuPatients
+			
+			# List a location's data in a date range
+			covid19 list data -l [location] --from 2020-03-01 --to 2020-03-31
+			
+			# Export a location's data and the dataset's provenance as JSON
+			covid19 list data -l [location] --format json
+			
+			# List a state's records, or the latest values of its counties
+			covid19 --source nyt-counties list data -l "United States/New York"
+			covid19 --source nyt-counties list data -l "United States/New York" --regions
+			
+			# List the sum of a group of locations, such as a continent, the
+			# European Union, the G7 or a group from the configuration file
+			covid19 list data -l G7
+			
+			# Rank locations of at least a million people by deaths per million
+			covid19 list data --perCapita --minPopulation 1000000 --sortBy totalDeaths
+			
+			# List the daily new cases of locations side by side on a common
+			# calendar, with days that a location didn't report interpolated
+			covid19 list series -l Italy -l Spain -v newCases --missing interpolate
+			
+			# Only list the days that every location has data for
+			covid19 list series -l Italy -l "South Korea" --align intersect`,
 	}
 }
 
@@ -71,7 +160,7 @@ func (h *ListCommandHandler) Command() *cli.Command {
 					&cli.StringFlag{
 						Name:        "location",
 						Aliases:     []string{"l"},
-						Usage:       "Filter by location.",
+						Usage:       "Filter by location or location group.",
 						Required:    false,
 						Destination: &h.location,
 					},
@@ -85,7 +174,7 @@ func (h *ListCommandHandler) Command() *cli.Command {
 					&cli.StringFlag{
 						Name:        "sortBy",
 						Aliases:     []string{"sb"},
-						Usage:       "Sort by name, newCases, newDeaths, totalCases or totalDeaths.",
+						Usage:       "Sort by name, " + metricUsage + ".",
 						Required:    false,
 						Destination: &h.sortBy,
 					},
@@ -96,6 +185,122 @@ func (h *ListCommandHandler) Command() *cli.Command {
 						Required:    false,
 						Destination: &h.sortOrder,
 					},
+					&cli.StringFlag{
+						Name:        "metrics",
+						Aliases:     []string{"m"},
+						Usage:       "Comma separated metrics to list from " + metricUsage + ".",
+						Required:    false,
+						Destination: &h.metrics,
+					},
+					&cli.StringFlag{
+						Name:        "from",
+						Usage:       "List records from the date (YYYY-MM-DD).",
+						Required:    false,
+						Destination: &h.from,
+					},
+					&cli.StringFlag{
+						Name:        "to",
+						Usage:       "List records up to the date (YYYY-MM-DD).",
+						Required:    false,
+						Destination: &h.to,
+					},
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "The output format, table or json.",
+						Required:    false,
+						Value:       "table",
+						Destination: &h.format,
+					},
+					&cli.BoolFlag{
+						Name:        "perCapita",
+						Aliases:     []string{"pc"},
+						Usage:       "List and sort by values per million people.",
+						Required:    false,
+						Destination: &h.perCapita,
+					},
+					&cli.IntFlag{
+						Name:        "minPopulation",
+						Aliases:     []string{"mp"},
+						Usage:       "Only list locations with at least the given population.",
+						Required:    false,
+						Destination: &h.minPopulation,
+					},
+					&cli.BoolFlag{
+						Name:        "regions",
+						Aliases:     []string{"r"},
+						Usage:       "List the regions of the location, such as the states of a country.",
+						Required:    false,
+						Destination: &h.regions,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "Load the dataset from a CSV, gzip or zip file, or - for standard input.",
+						Required: false,
+					},
+				},
+			},
+			&cli.Command{
+				Name:    "series",
+				Aliases: []string{"s"},
+				Action:  h.ListSeriesAction,
+				Usage:   "The daily series of locations on a common calendar.",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:     "location",
+						Aliases:  []string{"l"},
+						Usage:    "A location or location group to list. May be repeated. Lists the world if omitted.",
+						Required: false,
+					},
+					&cli.StringFlag{
+						Name:        "value",
+						Aliases:     []string{"v"},
+						Usage:       "List " + metricUsage + ".",
+						Required:    false,
+						Value:       "totalCases",
+						Destination: &h.value,
+					},
+					&cli.StringFlag{
+						Name:        "missing",
+						Aliases:     []string{"m"},
+						Usage:       "Fill days without a value with " + strings.Join(models.MissingPolicyNames(), ", ") + ".",
+						Required:    false,
+						Value:       "skip",
+						Destination: &h.missing,
+					},
+					&cli.StringFlag{
+						Name:        "align",
+						Aliases:     []string{"a"},
+						Usage:       "Align the series to the " + strings.Join(models.AlignModeNames(), " or ") + " of their date ranges.",
+						Required:    false,
+						Value:       "union",
+						Destination: &h.align,
+					},
+					&cli.StringFlag{
+						Name:        "from",
+						Usage:       "List the series from the date (YYYY-MM-DD).",
+						Required:    false,
+						Destination: &h.from,
+					},
+					&cli.StringFlag{
+						Name:        "to",
+						Usage:       "List the series up to the date (YYYY-MM-DD).",
+						Required:    false,
+						Destination: &h.to,
+					},
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "The output format, table or json.",
+						Required:    false,
+						Value:       "table",
+						Destination: &h.format,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "Load the dataset from a CSV, gzip or zip file, or - for standard input.",
+						Required: false,
+					},
 				},
 			},
 		},
@@ -104,16 +309,65 @@ func (h *ListCommandHandler) Command() *cli.Command {
 
 // ListDataSetAction lists the full dataset.
 func (h *ListCommandHandler) ListDataSetAction(c *cli.Context) error {
-	if os.Getenv("UPDATE_DATA") == "true" {
-		// Update our data set
-		err := updateDataset(localPath, dataSetURL)
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
+	}
+
+	var err error
+	metrics := models.DefaultMetrics
+	if len(h.metrics) > 0 {
+		metrics, err = models.ParseMetrics(h.metrics)
 		if err != nil {
 			return err
 		}
 	}
 
+	filter, err := h.recordFilter()
+	if err != nil {
+		return err
+	}
+
+	if h.regions && (len(h.location) == 0 || h.world) {
+		return errors.New("the regions flag requires a location")
+	}
+
+	printer := &listPrinter{metrics: metrics, json: h.format == "json", perCapita: h.perCapita}
+
+	updated, err := updateIfRequested(c)
+	if err != nil {
+		return err
+	}
+
+	// Stream a location's records instead of loading the world if we can.
+	// Groups are summed from the world's locations, and locations that aren't
+	// found are looked up in the world to suggest similar names. An updated
+	// dataset is parsed so that its world is cached.
+	if len(h.location) > 0 && !h.world && !h.regions && !updated {
+		table, groups, err := loadLocations(c)
+		if err != nil {
+			return err
+		}
+
+		if _, isGroup := groups.Lookup(h.location); !isGroup {
+			filter.Aliases = locationNames(table, h.location)
+
+			reader, ok, err := openRecords(c, filter)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				found, err := h.listRecords(c, reader, printer, table)
+				reader.Close()
+				if found || err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	// Get the world locations
-	world, err := models.NewWorldFromPath(localPath)
+	world, err := parseWorld(c, updated)
 	if err != nil {
 		return err
 	}
@@ -126,35 +380,500 @@ func (h *ListCommandHandler) ListDataSetAction(c *cli.Context) error {
 		h.sortOrder = "desc"
 	}
 
-	world.Sort(h.sortBy, h.sortOrder)
+	// List the location's regions in place of the world's locations
+	if h.regions {
+		l, err := findLocation(c, world, h.location)
+		if err != nil {
+			return err
+		}
 
-	if h.world {
-		fmt.Printf("%-32s %-32s %-12s %-12s %-12s %-12s", "Date", "Location", "New Cases", "New Deaths", "Total Cases", "Total Deaths\n")
+		if len(l.Children) == 0 {
+			return fmt.Errorf("%s doesn't have any regions", l.Path())
+		}
+		world = models.NewWorld(l.Children, l.Records)
+	}
+
+	if h.perCapita {
+		world.SortPerMillion(h.sortBy, h.sortOrder)
+	} else {
+		world.Sort(h.sortBy, h.sortOrder)
+	}
 
+	listsRecords := h.world || len(h.location) > 0 && !h.regions
+	if err = printer.begin(c, listsRecords); err != nil {
+		return err
+	}
+
+	if h.world {
+		population := world.Population()
 		for _, r := range world.Records {
-			fmt.Printf(r.String() + "\n")
+			if filter.Selects(r) {
+				printer.printRecord(r, population)
+			}
 		}
 	} else {
-		if len(h.location) > 0 {
-			fmt.Printf("%-32s %-32s %-12s %-12s %-12s %-12s", "Date", "Location", "New Cases", "New Deaths", "Total Cases", "Total Deaths\n")
+		if len(h.location) > 0 && !h.regions {
+			l, err := findLocation(c, world, h.location)
+			if err != nil {
+				return err
+			}
 
+			filter.Location = l.Name
+			population := l.Population()
+			for _, r := range l.Records {
+				if filter.Selects(r) {
+					printer.printRecord(r, population)
+				}
+			}
+		} else {
 			for _, l := range world.Locations {
-				if len(h.location) > 0 && strings.ToLower(l.Name) != strings.ToLower(h.location) {
+				if h.minPopulation > 0 && l.Population() < h.minPopulation {
 					continue
 				}
+				printer.printLocation(l)
+			}
+		}
+	}
 
-				for _, r := range l.Records {
-					fmt.Printf(r.String() + "\n")
-				}
+	printer.end()
+	return nil
+}
+
+// ListSeriesAction lists the series of a metric of locations aligned to a
+// common daily calendar. Days that a location doesn't have a value on are
+// filled according to the missing value policy, and the skip policy leaves
+// them empty.
+func (h *ListCommandHandler) ListSeriesAction(c *cli.Context) error {
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
+	}
+
+	metric, err := models.ParseMetric(h.value)
+	if err != nil {
+		return err
+	}
+
+	policy, err := models.ParseMissingPolicy(h.missing)
+	if err != nil {
+		return err
+	}
+
+	mode, err := models.ParseAlignMode(h.align)
+	if err != nil {
+		return err
+	}
+
+	world, err := loadWorld(c)
+	if err != nil {
+		return err
+	}
+
+	var series []*models.Series
+	names := c.StringSlice("location")
+	if len(names) == 0 {
+		series = append(series, world.Series(metric))
+	}
+
+	for _, name := range names {
+		l, err := findLocation(c, world, name)
+		if err != nil {
+			return err
+		}
+
+		s := l.Series(metric)
+		if l.Parent != nil {
+			s.Name = l.Path()
+		}
+		series = append(series, s)
+	}
+
+	series = models.AlignSeries(series, mode)
+	if series, err = h.seriesRange(series); err != nil {
+		return err
+	}
+
+	// The series share the calendar until the skip policy drops their gaps
+	calendar := series[0].Dates
+	for i, s := range series {
+		series[i] = s.Fill(policy)
+	}
+
+	if h.format == "json" {
+		return h.printSeriesListing(c, metric, policy, mode, calendar, series)
+	}
+
+	// Columns are wide enough for the names of regions' paths
+	widths := make([]int, len(series))
+	header := fmt.Sprintf("%-12s", "Date")
+	for i, s := range series {
+		widths[i] = 20
+		if len(s.Name) > widths[i] {
+			widths[i] = len(s.Name)
+		}
+		header += fmt.Sprintf(" %-*s", widths[i], s.Name)
+	}
+	fmt.Println(strings.TrimRight(header, " "))
+
+	for _, date := range calendar {
+		row := fmt.Sprintf("%-12s", date.Format("2006-01-02"))
+		for i, s := range series {
+			row += fmt.Sprintf(" %-*s", widths[i], formatSeriesValue(s, date))
+		}
+		fmt.Println(strings.TrimRight(row, " "))
+	}
+
+	if policy != models.MissingSkip {
+		fmt.Printf("\n* filled with the %s policy\n", policy)
+	}
+	return nil
+}
+
+// MARK: Unexported methods
+
+// seriesRange returns the aligned series limited to the from and to dates.
+func (h *ListCommandHandler) seriesRange(series []*models.Series) ([]*models.Series, error) {
+	if len(series) == 0 || series[0].Len() == 0 || len(h.from) == 0 && len(h.to) == 0 {
+		return series, nil
+	}
+
+	from, to := series[0].Start(), series[0].End()
+	if len(h.from) > 0 {
+		date, err := parseTime(h.from, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if date.After(from) {
+			from = date
+		}
+	}
+
+	if len(h.to) > 0 {
+		date, err := parseTime(h.to, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if date.Before(to) {
+			to = date
+		}
+	}
+
+	limited := make([]*models.Series, len(series))
+	for i, s := range series {
+		limited[i] = s.Reindex(from, to)
+	}
+	return limited, nil
+}
+
+// printSeriesListing prints the series with the dataset's provenance as
+// JSON.
+func (h *ListCommandHandler) printSeriesListing(c *cli.Context, metric models.Metric, policy models.MissingPolicy, mode models.AlignMode, calendar []time.Time, series []*models.Series) error {
+	dataset, err := loadProvenance(c)
+	if err != nil {
+		return err
+	}
+
+	listing := seriesListing{
+		Dataset: dataset,
+		Metric:  metric,
+		Missing: policy.String(),
+		Align:   mode.String(),
+		Dates:   make([]string, len(calendar)),
+	}
+
+	for i, date := range calendar {
+		listing.Dates[i] = date.Format("2006-01-02")
+	}
+
+	for _, s := range series {
+		values := &seriesValues{
+			Location: s.Name,
+			Values:   make([]*float64, len(calendar)),
+			Reported: make([]bool, len(calendar)),
+		}
+
+		for i, date := range calendar {
+			if j := s.Index(date); j >= 0 {
+				values.Values[i] = &s.Values[j]
+				values.Reported[i] = s.Reported[j]
 			}
+		}
+		listing.Series = append(listing.Series, values)
+	}
+
+	data, err := json.MarshalIndent(listing, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// recordFilter returns the filter of the location and date flags. The world
+// flag takes precedence over the location.
+func (h *ListCommandHandler) recordFilter() (*models.RecordFilter, error) {
+	filter := &models.RecordFilter{}
+	if !h.world {
+		filter.Location = h.location
+	}
+
+	var err error
+	if len(h.from) > 0 {
+		if filter.From, err = parseTime(h.from, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(h.to) > 0 {
+		if filter.To, err = parseTime(h.to, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// listRecords lists the records read by reader, and returns whether or not
+// there were any. Nothing is printed if there aren't.
+func (h *ListCommandHandler) listRecords(c *cli.Context, reader *models.RecordReader, printer *listPrinter, table *models.LocationTable) (bool, error) {
+	if !reader.Next() {
+		return false, reader.Err()
+	}
+
+	// Streamed records don't have a location to take the population from
+	population, _ := table.Population(h.location)
+
+	if err := printer.begin(c, true); err != nil {
+		return true, err
+	}
+
+	for ok := true; ok; ok = reader.Next() {
+		printer.printRecord(reader.Record(), population)
+	}
+
+	if err := reader.Err(); err != nil {
+		return true, err
+	}
+
+	printer.end()
+	printDiagnostics(reader.Diagnostics())
+	return true, nil
+}
+
+// begin prints the table's header, or the start of the JSON object. Records
+// and locations are listed with different columns.
+func (p *listPrinter) begin(c *cli.Context, records bool) error {
+	if !p.json {
+		if records {
+			fmt.Println(recordHeader(p.metrics, p.perCapita))
 		} else {
-			fmt.Printf("%-32s %-12s %-12s %-12s %-12s", "Location", "New Cases", "New Deaths", "Total Cases", "Total Deaths\n")
+			fmt.Println(locationHeader(p.metrics, p.perCapita))
+		}
+		return nil
+	}
 
-			for _, l := range world.Locations {
-				fmt.Printf(l.String() + "\n")
+	dataset, err := loadProvenance(c)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(dataset, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	key := "locations"
+	if records {
+		key = "records"
+	}
+
+	fmt.Printf("{\n  \"dataset\": %s,\n  %q: [", data, key)
+	return nil
+}
+
+// printRecord prints the row of a record. Values per million people use the
+// given population, or the population reported by the record if it isn't
+// known.
+func (p *listPrinter) printRecord(r *models.COVRecord, population int) {
+	if p.perCapita && population <= 0 {
+		population, _ = r.Lookup(models.MetricPopulation)
+	}
+
+	if !p.json {
+		fmt.Println(recordRow(r, p.metrics, p.perCapita, population))
+		return
+	}
+
+	p.printJSON(recordValues(r, p.metrics, p.perCapita, population))
+}
+
+// printLocation prints the row of a location's latest record.
+func (p *listPrinter) printLocation(l *models.Location) {
+	population := l.Population()
+
+	if !p.json {
+		fmt.Println(locationRow(l, p.metrics, p.perCapita, population))
+		return
+	}
+
+	var values map[string]interface{}
+	if len(l.Records) > 0 {
+		values = recordValues(l.Records[len(l.Records)-1], p.metrics, p.perCapita, population)
+	} else {
+		values = map[string]interface{}{"location": l.Name}
+	}
+
+	if p.perCapita {
+		values["population"] = nil
+		if population > 0 {
+			values["population"] = population
+		}
+
+		if l.Info != nil {
+			values["iso3"] = l.Info.ISO3
+			values["continent"] = l.Info.Continent
+		}
+	}
+	p.printJSON(values)
+}
+
+// end prints the end of the JSON object.
+func (p *listPrinter) end() {
+	if !p.json {
+		return
+	}
+
+	if p.rows > 0 {
+		fmt.Print("\n  ")
+	}
+	fmt.Println("]\n}")
+}
+
+// printJSON prints a row of the JSON array.
+func (p *listPrinter) printJSON(row interface{}) {
+	data, _ := json.MarshalIndent(row, "    ", "  ")
+
+	if p.rows > 0 {
+		fmt.Print(",")
+	}
+	fmt.Printf("\n    %s", data)
+	p.rows++
+}
+
+// MARK: Unexported functions
+
+// recordHeader returns the header of a record table with the given metrics.
+func recordHeader(metrics []models.Metric, perCapita bool) string {
+	header := fmt.Sprintf("%-32s %-32s", "Date", "Location")
+	return strings.TrimRight(header+metricHeader(metrics, perCapita), " ")
+}
+
+// recordRow returns the row of a record table with the given metrics, per
+// million people of the population if perCapita is true.
+func recordRow(r *models.COVRecord, metrics []models.Metric, perCapita bool, population int) string {
+	row := fmt.Sprintf("%-32v %-32s", r.Date, r.Location)
+	return row + metricRow(r, metrics, perCapita, population)
+}
+
+// recordValues returns the date, location and values of the given metrics of
+// a record keyed by name. Missing values are nil. If perCapita is true, values
+// are per million people of the population and their names have the suffix
+// "PerMillion".
+func recordValues(r *models.COVRecord, metrics []models.Metric, perCapita bool, population int) map[string]interface{} {
+	values := map[string]interface{}{
+		"date":     r.Date.Format("2006-01-02"),
+		"location": r.Location,
+	}
+
+	for _, metric := range metrics {
+		if scalesPerMillion(metric, perCapita) {
+			if value, ok := r.PerMillion(metric, population); ok {
+				values[metric.Name()+"PerMillion"] = value
+			} else {
+				values[metric.Name()+"PerMillion"] = nil
 			}
+		} else if value, ok := r.Lookup(metric); ok {
+			values[metric.Name()] = value
+		} else {
+			values[metric.Name()] = nil
 		}
 	}
 
-	return nil
+	return values
+}
+
+// locationHeader returns the header of a location table with the given
+// metrics.
+func locationHeader(metrics []models.Metric, perCapita bool) string {
+	header := fmt.Sprintf("%-32s", "Location")
+	return strings.TrimRight(header+metricHeader(metrics, perCapita), " ")
+}
+
+// locationRow returns the row of a location table with the given metrics, per
+// million people of the population if perCapita is true.
+func locationRow(l *models.Location, metrics []models.Metric, perCapita bool, population int) string {
+	var last *models.COVRecord
+	if len(l.Records) > 0 {
+		last = l.Records[len(l.Records)-1]
+	}
+
+	row := fmt.Sprintf("%-32s", l.Name)
+	return row + metricRow(last, metrics, perCapita, population)
+}
+
+// metricHeader returns the metric columns of a table's header. Per capita
+// columns are wider to fit their titles.
+func metricHeader(metrics []models.Metric, perCapita bool) string {
+	header := ""
+	for _, metric := range metrics {
+		if scalesPerMillion(metric, perCapita) {
+			header += fmt.Sprintf(" %-16s", metric.Title()+"/M")
+		} else {
+			header += fmt.Sprintf(" %-12s", metric.Title())
+		}
+	}
+	return header
+}
+
+// metricRow returns the metric columns of a record's row, per million people
+// of the population if perCapita is true.
+func metricRow(r *models.COVRecord, metrics []models.Metric, perCapita bool, population int) string {
+	row := ""
+	for _, metric := range metrics {
+		if scalesPerMillion(metric, perCapita) {
+			row += fmt.Sprintf(" %-16s", r.FormatPerMillion(metric, population))
+		} else {
+			row += fmt.Sprintf(" %-12s", r.FormatValue(metric))
+		}
+	}
+	return row
+}
+
+// formatSeriesValue returns the series' value of the date as a string, or
+// "n/a" if the series doesn't have one. Filled values are marked with an
+// asterisk.
+func formatSeriesValue(s *models.Series, date time.Time) string {
+	i := s.Index(date)
+	if i < 0 {
+		return "n/a"
+	}
+
+	value := s.Values[i]
+	formatted := strconv.FormatFloat(value, 'f', 2, 64)
+	if value == math.Trunc(value) {
+		formatted = strconv.FormatFloat(value, 'f', 0, 64)
+	}
+
+	if !s.Reported[i] {
+		formatted += "*"
+	}
+	return formatted
+}
+
+// scalesPerMillion returns whether or not values of the metric are listed per
+// million people. Populations are listed as they are.
+func scalesPerMillion(metric models.Metric, perCapita bool) bool {
+	return perCapita && metric != models.MetricPopulation
 }
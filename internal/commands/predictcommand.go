@@ -2,12 +2,15 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/colinc86/covid-19/internal/models"
+	"github.com/colinc86/covid-19/internal/provenance"
 	"github.com/colinc86/go-genetics"
 	"github.com/urfave/cli/v2"
 )
@@ -21,8 +24,47 @@ type PredictCommandHandler struct {
 
 	// MARK: Private properties
 	location string
+	value    string
+	missing  string
 	days     uint
+	format   string
 	signal   []float64
+	offsets  []int
+	start    time.Time
+}
+
+// forecast types contain a prediction and the provenance of the dataset that
+// it was fitted to.
+type forecast struct {
+
+	// The provenance of the dataset's files.
+	Dataset []*provenance.Metadata `json:"dataset"`
+
+	// The predicted location, or empty for the world.
+	Location string `json:"location,omitempty"`
+
+	// The predicted metric.
+	Metric models.Metric `json:"metric"`
+
+	// The policy that missing values were treated with.
+	Missing string `json:"missing"`
+
+	// The number of days on the daily calendar that didn't have a reported
+	// value.
+	Gaps int `json:"gaps"`
+
+	// The coefficients of the fitted sigmoid function.
+	Coefficients []float64 `json:"coefficients"`
+
+	// The dates of the metric's values, and the metric's values and the
+	// fitted function's values on the same days.
+	Dates  []string  `json:"dates"`
+	Actual []float64 `json:"actual"`
+	Fitted []float64 `json:"fitted"`
+
+	// The predicted values of the days after the last date.
+	PredictedDates []string  `json:"predictedDates"`
+	Predicted      []float64 `json:"predicted"`
 }
 
 // MARK: Initializers
@@ -36,6 +78,11 @@ func NewPredictCommandHandler() *PredictCommandHandler {
 		Description: `Predicts future values in the dataset by extrapolating
 		a Sigmoid curve.
 		
+		Values are placed on a continuous daily calendar so that the curve is
+		fitted against days rather than records. Days without a reported value
+		are filled according to the missing value policy, or left out of the
+		fit with the skip policy.
+		
 		Examples:
 			# Predict world data for the next day
 			covid19 predict data
@@ -44,7 +91,13 @@ func NewPredictCommandHandler() *PredictCommandHandler {
 			covid19 predict data -l [location]
 			
 			# Predict data number days out
-			covid19 predict data -d [number]`,
+			covid19 predict data -d [number]
+			
+			# Predict a different value
+			covid19 predict data -v totalVaccinations
+			
+			# Print the forecast and the dataset's provenance as JSON
+			covid19 predict data --format json`,
 	}
 }
 
@@ -67,10 +120,26 @@ func (h *PredictCommandHandler) Command() *cli.Command {
 					&cli.StringFlag{
 						Name:        "location",
 						Aliases:     []string{"l"},
-						Usage:       "Filter by location.",
+						Usage:       "Filter by location or location group.",
 						Required:    false,
 						Destination: &h.location,
 					},
+					&cli.StringFlag{
+						Name:        "value",
+						Aliases:     []string{"v"},
+						Usage:       "Predict by " + metricUsage + ".",
+						Required:    false,
+						Value:       "totalCases",
+						Destination: &h.value,
+					},
+					&cli.StringFlag{
+						Name:        "missing",
+						Aliases:     []string{"m"},
+						Usage:       "Treat missing values with " + strings.Join(models.MissingPolicyNames(), ", ") + ".",
+						Required:    false,
+						Value:       "interpolate",
+						Destination: &h.missing,
+					},
 					&cli.UintFlag{
 						Name:        "days",
 						Aliases:     []string{"d"},
@@ -79,6 +148,19 @@ func (h *PredictCommandHandler) Command() *cli.Command {
 						Value:       1,
 						Destination: &h.days,
 					},
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "The output format, table or json.",
+						Required:    false,
+						Value:       "table",
+						Destination: &h.format,
+					},
+					&cli.StringFlag{
+						Name:     "file",
+						Aliases:  []string{"f"},
+						Usage:    "Load the dataset from a CSV, gzip or zip file, or - for standard input.",
+						Required: false,
+					},
 				},
 			},
 		},
@@ -92,38 +174,60 @@ func (h *PredictCommandHandler) PredictDataSetAction(c *cli.Context) error {
 		h.days = 1
 	}
 
-	// Get the data set
-	if os.Getenv("UPDATE_DATA") == "true" {
-		// Update our data set
-		err := updateDataset(localPath, dataSetURL)
-		if err != nil {
-			return err
-		}
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
 	}
 
 	// Get the world locations
-	world, err := models.NewWorldFromPath(localPath)
+	world, err := loadWorld(c)
+	if err != nil {
+		return err
+	}
+
+	metric, err := models.ParseMetric(h.value)
+	if err != nil {
+		return err
+	}
+
+	policy, err := models.ParseMissingPolicy(h.missing)
 	if err != nil {
 		return err
 	}
 
-	// Get the current series
-	var totalCases []float64
+	// Get the current series on a daily calendar
+	var series *models.Series
 	if len(h.location) > 0 {
-		totalCases = world.TotalCasesSignalForLocation(h.location)
+		l, err := findLocation(c, world, h.location)
+		if err != nil {
+			return err
+		}
+
+		series = l.Series(metric).Daily()
 		// totalDeaths = world.TotalDeathsSignalForLocation(h.location)
 	} else {
-		totalCases = world.TotalCasesSignal()
+		series = world.Series(metric).Daily()
 		// totalDeaths = world.TotalDeathsSignal()
 	}
 
+	gaps := series.Gaps()
+	if series.Len() == gaps {
+		return fmt.Errorf("there are no %s values to predict from", metric.Title())
+	}
+	filled := series.Fill(policy)
+
+	totalCases := filled.Values
+	h.setSeries(filled)
+
 	// Get sigmoid function coefficients and solve
-	h.signal = totalCases
-	casesCoefficients := h.analyzeSignal("cases", totalCases)
+	casesCoefficients := h.analyzeSignal(metric.Title(), totalCases)
+
+	if h.format == "json" {
+		return h.printForecast(c, metric, policy, gaps, filled, casesCoefficients)
+	}
 
 	// Print the current bars and predicted past bars
 	for i, actualValue := range totalCases {
-		predictedValue := h.sigmoidFunction(casesCoefficients, i)
+		predictedValue := h.sigmoidFunction(casesCoefficients, h.offsets[i])
 
 		bar := ""
 		ticks := int(math.Ceil(float64(actualValue) / (totalCases[len(totalCases)-1] / 40.0)))
@@ -137,13 +241,14 @@ func (h *PredictCommandHandler) PredictDataSetAction(c *cli.Context) error {
 			pbar += "+"
 		}
 
-		fmt.Printf("          %-12d %s\n", int(actualValue), bar)
-		fmt.Printf("%-9d %-12d %s\n", -1*len(totalCases)+i+1, int(predictedValue), pbar)
+		fmt.Printf("           %-12d %s\n", int(actualValue), bar)
+		fmt.Printf("%-10s %-12d %s\n", filled.Dates[i].Format("2006-01-02"), int(predictedValue), pbar)
 	}
 
 	// Print the predicted future bars
-	for i := 0; i < int(h.days); i++ {
-		predictedValue := h.sigmoidFunction(casesCoefficients, len(totalCases)+i)
+	last := h.offsets[len(h.offsets)-1]
+	for i := 1; i <= int(h.days); i++ {
+		predictedValue := h.sigmoidFunction(casesCoefficients, last+i)
 
 		pbar := ""
 		pticks := int(math.Ceil(float64(predictedValue) / (totalCases[len(totalCases)-1] / 40.0)))
@@ -151,7 +256,7 @@ func (h *PredictCommandHandler) PredictDataSetAction(c *cli.Context) error {
 			pbar += "+"
 		}
 
-		fmt.Printf("\n%-9d %-12d %s\n", i+1, int(predictedValue), pbar)
+		fmt.Printf("\n%-10s %-12d %s\n", h.date(last+i).Format("2006-01-02"), int(predictedValue), pbar)
 	}
 
 	fmt.Printf("coeff: %v\n", casesCoefficients)
@@ -165,6 +270,65 @@ func (h *PredictCommandHandler) PredictDataSetAction(c *cli.Context) error {
 
 // MARK: Unexported methods
 
+// setSeries sets the signal to fit to the series' values, and the days of
+// the values to the number of days since the series' first date.
+func (h *PredictCommandHandler) setSeries(series *models.Series) {
+	h.signal = series.Values
+	h.start = series.Start()
+	h.offsets = make([]int, series.Len())
+	for i, date := range series.Dates {
+		h.offsets[i] = int(date.Sub(h.start).Hours() / 24)
+	}
+}
+
+// date returns the date of the given number of days since the series' first
+// date.
+func (h *PredictCommandHandler) date(day int) time.Time {
+	return h.start.AddDate(0, 0, day)
+}
+
+// printForecast prints the forecast of the series with the coefficients and
+// the dataset's provenance as JSON.
+func (h *PredictCommandHandler) printForecast(c *cli.Context, metric models.Metric, policy models.MissingPolicy, gaps int, series *models.Series, coefficients []float64) error {
+	dataset, err := loadProvenance(c)
+	if err != nil {
+		return err
+	}
+
+	f := forecast{
+		Dataset:        dataset,
+		Location:       h.location,
+		Metric:         metric,
+		Missing:        policy.String(),
+		Gaps:           gaps,
+		Coefficients:   coefficients,
+		Dates:          make([]string, series.Len()),
+		Actual:         h.signal,
+		Fitted:         make([]float64, len(h.signal)),
+		PredictedDates: make([]string, h.days),
+		Predicted:      make([]float64, h.days),
+	}
+
+	for i := range h.signal {
+		f.Dates[i] = series.Dates[i].Format("2006-01-02")
+		f.Fitted[i] = h.sigmoidFunction(coefficients, h.offsets[i])
+	}
+
+	last := h.offsets[len(h.offsets)-1]
+	for i := range f.Predicted {
+		f.PredictedDates[i] = h.date(last + i + 1).Format("2006-01-02")
+		f.Predicted[i] = h.sigmoidFunction(coefficients, last+i+1)
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
 // analyzeCases analyzes the signal.
 func (h *PredictCommandHandler) analyzeSignal(name string, signal []float64) []float64 {
 	s := NewSpinnerWithTitle(fmt.Sprintf("Analyzing %s...", name))
@@ -224,7 +388,7 @@ func (h *PredictCommandHandler) totalError(genes []float64) float64 {
 
 	totalErr := 0.0
 	for i, v := range h.signal {
-		totalErr += math.Abs(h.sigmoidFunction(genes, i) - v)
+		totalErr += math.Abs(h.sigmoidFunction(genes, h.offsets[i]) - v)
 	}
 	return totalErr / float64(len(h.signal))
 }
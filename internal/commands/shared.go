@@ -2,12 +2,18 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/colinc86/covid-19/internal/models"
 	"github.com/superhawk610/bar"
 )
 
+// metricUsage lists the metric names for flag usage strings.
+var metricUsage = strings.Join(models.MetricNames(), ", ")
+
 // NewBarWithTitle creates a new bar with the given title and number of ticks.
 func NewBarWithTitle(title string, n int) *bar.Bar {
 	return bar.NewWithOpts(
@@ -23,9 +29,81 @@ func NewBarWithTitle(title string, n int) *bar.Bar {
 	)
 }
 
-// NewSpinnerWithTitle creates a new spinner with the given title.
+// NewSpinnerWithTitle creates a new spinner with the given title. Spinners
+// are written to standard error so that they don't mix with JSON output.
 func NewSpinnerWithTitle(title string) *spinner.Spinner {
 	s := spinner.New(spinner.CharSets[9], 50*time.Millisecond)
 	s.Suffix = fmt.Sprintf(" %s", title)
+	s.Writer = os.Stderr
 	return s
 }
+
+// fetchProgress types display the progress of a download with a spinner
+// until the size of the download is known and a bar afterwards.
+type fetchProgress struct {
+	title     string
+	spinner   *spinner.Spinner
+	bar       *bar.Bar
+	total     int64
+	written   int64
+	kilobytes int
+}
+
+// newFetchProgress creates and returns a new fetch progress with the given
+// title and starts its spinner.
+func newFetchProgress(title string) *fetchProgress {
+	p := &fetchProgress{
+		title:   title,
+		spinner: NewSpinnerWithTitle(title),
+	}
+	p.spinner.Start()
+	return p
+}
+
+// Start replaces the spinner with a bar when the total size is known.
+func (p *fetchProgress) Start(total int64, written int64) {
+	p.total = total
+	p.written = written
+	if total <= 0 || !isTerminal(os.Stdout) {
+		return
+	}
+
+	p.spinner.Stop()
+	p.kilobytes = int(written / 1024)
+	p.bar = NewBarWithTitle(p.title, int(total/1024)+1)
+	p.bar.Update(p.kilobytes, nil)
+}
+
+// Add advances the bar by n bytes.
+func (p *fetchProgress) Add(n int) {
+	p.written += int64(n)
+	if p.bar == nil {
+		return
+	}
+
+	// Only redraw the bar when a whole kilobyte has been written.
+	if kilobytes := int(p.written / 1024); kilobytes != p.kilobytes {
+		p.kilobytes = kilobytes
+		p.bar.Update(kilobytes, nil)
+	}
+}
+
+// Finish completes the bar.
+func (p *fetchProgress) Finish() {
+	if p.bar != nil {
+		p.bar.Update(int(p.total/1024)+1, nil)
+		p.bar.Done()
+		p.bar = nil
+	}
+}
+
+// Stop stops the spinner.
+func (p *fetchProgress) Stop() {
+	p.spinner.Stop()
+}
+
+// isTerminal returns whether or not the file is a character device.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,229 @@
+// Package commands conatins the commands for the medina command line application.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/snapshots"
+	"github.com/urfave/cli/v2"
+)
+
+// SnapshotsCommandHandler handles snapshots commands.
+type SnapshotsCommandHandler struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+
+	// MARK: Private properties
+	keep   int
+	before string
+}
+
+// MARK: Initializers
+
+// NewSnapshotsCommandHandler creates and returns a new snapshots command
+// handler.
+func NewSnapshotsCommandHandler() *SnapshotsCommandHandler {
+	return &SnapshotsCommandHandler{
+		Name:    "snapshots",
+		Aliases: []string{"s"},
+		Usage:   "Manages the dataset snapshots.",
+		Description: `Manage the snapshots of every distinct dataset download.
+		
+		Examples:
+			# List the snapshots
+			covid19 snapshots list
+			
+			# Show a snapshot
+			covid19 snapshots show [id]
+			
+			# Remove all but the newest 10 snapshots of each file
+			covid19 snapshots prune --keep 10
+			
+			# Remove snapshots taken before a date
+			covid19 snapshots prune --before 2020-04-01
+			
+			# List the data as it was on a date
+			covid19 --as-of 2020-04-01 list data`,
+	}
+}
+
+// MARK: Public methods
+
+// Command creates and returns the handler's command.
+func (h *SnapshotsCommandHandler) Command() *cli.Command {
+	return &cli.Command{
+		Name:        h.Name,
+		Aliases:     h.Aliases,
+		Usage:       h.Usage,
+		Description: h.Description,
+		Subcommands: []*cli.Command{
+			&cli.Command{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Action:  h.ListSnapshotsAction,
+				Usage:   "Lists the snapshots.",
+			},
+			&cli.Command{
+				Name:      "show",
+				Aliases:   []string{"s"},
+				Action:    h.ShowSnapshotAction,
+				Usage:     "Shows a snapshot.",
+				ArgsUsage: "[id]",
+			},
+			&cli.Command{
+				Name:    "prune",
+				Aliases: []string{"p"},
+				Action:  h.PruneSnapshotsAction,
+				Usage:   "Removes old snapshots.",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:        "keep",
+						Aliases:     []string{"k"},
+						Usage:       "The number of newest snapshots of each file to keep.",
+						Required:    false,
+						Destination: &h.keep,
+					},
+					&cli.StringFlag{
+						Name:        "before",
+						Aliases:     []string{"b"},
+						Usage:       "Only remove snapshots taken before this date (YYYY-MM-DD) or time (RFC 3339).",
+						Required:    false,
+						Destination: &h.before,
+					},
+				},
+			},
+		},
+	}
+}
+
+// ListSnapshotsAction lists the snapshots.
+func (h *SnapshotsCommandHandler) ListSnapshotsAction(c *cli.Context) error {
+	store, err := h.store(c)
+	if err != nil {
+		return err
+	}
+
+	all, err := store.All()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-14s %-42s %-22s %s\n", "ID", "File", "Time", "Size")
+
+	for _, snapshot := range all {
+		fmt.Printf("%-14s %-42s %-22s %d\n", snapshot.ID(), snapshot.File, snapshot.Time.Format(time.RFC3339), snapshot.Size)
+	}
+
+	return nil
+}
+
+// ShowSnapshotAction shows the snapshot with the given id and a summary of
+// its data.
+func (h *SnapshotsCommandHandler) ShowSnapshotAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("expected a snapshot id")
+	}
+
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	store := snapshots.NewStore(paths.SnapshotDir())
+	snapshot, err := store.Find(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-16s %s\n", "ID", snapshot.ID())
+	fmt.Printf("%-16s %s\n", "File", snapshot.File)
+	fmt.Printf("%-16s %s\n", "Time", snapshot.Time.Format(time.RFC3339))
+	fmt.Printf("%-16s %s\n", "SHA-256", snapshot.Hash)
+	fmt.Printf("%-16s %d\n", "Size", snapshot.Size)
+	fmt.Printf("%-16s %s\n", "Path", snapshot.Path)
+
+	// Summarize the snapshot if it belongs to a single file data source.
+	source, err := loadSource(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	files := source.Files()
+	if len(files) != 1 || files[0].Name != snapshot.File {
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "covid19-snapshot-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err = snapshot.Extract(filepath.Join(dir, snapshot.File)); err != nil {
+		return err
+	}
+
+	world, err := source.Parse(dir, parseOptions(cfg))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-16s %d\n", "Locations", len(world.Locations))
+	if len(world.Records) > 0 {
+		fmt.Printf("%-16s %s\n", "First date", world.Records[0].Date.Format("2006-01-02"))
+		fmt.Printf("%-16s %s\n", "Last date", world.Records[len(world.Records)-1].Date.Format("2006-01-02"))
+	}
+	fmt.Printf("%-16s %d\n", "Total cases", world.TotalCases())
+
+	return nil
+}
+
+// PruneSnapshotsAction removes old snapshots.
+func (h *SnapshotsCommandHandler) PruneSnapshotsAction(c *cli.Context) error {
+	if !c.IsSet("keep") && !c.IsSet("before") {
+		return errors.New("expected --keep, --before or both")
+	}
+
+	if h.keep < 0 {
+		return errors.New("--keep must not be negative")
+	}
+
+	before := time.Now()
+	if len(h.before) > 0 {
+		var err error
+		if before, err = parseTime(h.before, false); err != nil {
+			return err
+		}
+	}
+
+	store, err := h.store(c)
+	if err != nil {
+		return err
+	}
+
+	removed, err := store.Prune(h.keep, before)
+	for _, snapshot := range removed {
+		fmt.Printf("Removed %s %s %s\n", snapshot.ID(), snapshot.File, snapshot.Time.Format(time.RFC3339))
+	}
+
+	return err
+}
+
+// MARK: Unexported methods
+
+// store returns the snapshot store of the resolved data directory.
+func (h *SnapshotsCommandHandler) store(c *cli.Context) (*snapshots.Store, error) {
+	_, paths, err := loadPaths(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots.NewStore(paths.SnapshotDir()), nil
+}
@@ -2,22 +2,39 @@
 package commands
 
 import (
-	"io"
+	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/colinc86/covid-19/internal/config"
+	"github.com/colinc86/covid-19/internal/fetch"
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/snapshots"
+	"github.com/colinc86/covid-19/internal/sources"
 	"github.com/urfave/cli/v2"
 )
 
-const dataSetURL = "https://covid.ourworldindata.org/data/full_data.csv"
-const localPath = "/usr/local/var/covid_full_data.csv"
-
 // UpdateCommandHandler handles update commands.
 type UpdateCommandHandler struct {
 	Name        string
 	Aliases     []string
 	Usage       string
 	Description string
+
+	// MARK: Private properties
+	url             string
+	checksum        string
+	mirror          string
+	proxy           string
+	caFile          string
+	certFile        string
+	keyFile         string
+	connectTimeout  string
+	responseTimeout string
+	timeout         string
 }
 
 // MARK: Initializers
@@ -32,7 +49,22 @@ func NewUpdateCommandHandler() *UpdateCommandHandler {
 		
 		Examples:
 			# Update all data
-			covid19 update data`,
+			covid19 update data
+			
+			# Update the data of a different source
+			covid19 --source ecdc update data
+			
+			# Update the data from a mirror
+			covid19 update data --mirror https://mirror.example.com/covid19
+			
+			# Update the data through a proxy that uses an internal certificate authority
+			covid19 update data --proxy http://proxy:3128 --caFile /etc/ssl/internal-ca.pem
+			
+			# Only install the dataset if it has the given checksum
+			covid19 update data --sha256 [checksum]
+			
+			# Send a header with every request
+			covid19 update data --header "Authorization: Bearer [token]"`,
 	}
 }
 
@@ -51,6 +83,74 @@ func (h *UpdateCommandHandler) Command() *cli.Command {
 				Aliases: []string{"d"},
 				Action:  h.UpdateDataSetAction,
 				Usage:   "The COVID-19 dataset.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "url",
+						Usage:       "Download a single file data source's dataset from the URL.",
+						Required:    false,
+						Destination: &h.url,
+					},
+					&cli.StringFlag{
+						Name:        "sha256",
+						Usage:       "Only install a single file data source's dataset if it has the SHA-256 checksum.",
+						Required:    false,
+						Destination: &h.checksum,
+					},
+					&cli.StringFlag{
+						Name:        "mirror",
+						Usage:       "Download the data source's files by name from the mirror's base URL.",
+						Required:    false,
+						Destination: &h.mirror,
+					},
+					&cli.StringFlag{
+						Name:        "proxy",
+						Usage:       "The URL of the HTTP and HTTPS proxy. Overrides $HTTP_PROXY and $HTTPS_PROXY.",
+						Required:    false,
+						Destination: &h.proxy,
+					},
+					&cli.StringFlag{
+						Name:        "caFile",
+						Usage:       "A PEM file of certificate authorities to trust in addition to the system's.",
+						Required:    false,
+						Destination: &h.caFile,
+					},
+					&cli.StringFlag{
+						Name:        "certFile",
+						Usage:       "A PEM file of the client certificate.",
+						Required:    false,
+						Destination: &h.certFile,
+					},
+					&cli.StringFlag{
+						Name:        "keyFile",
+						Usage:       "A PEM file of the client certificate's private key.",
+						Required:    false,
+						Destination: &h.keyFile,
+					},
+					&cli.StringFlag{
+						Name:        "connectTimeout",
+						Usage:       "The maximum time spent connecting to the server, e.g. 10s.",
+						Required:    false,
+						Destination: &h.connectTimeout,
+					},
+					&cli.StringFlag{
+						Name:        "responseTimeout",
+						Usage:       "The maximum time spent waiting for the server to respond.",
+						Required:    false,
+						Destination: &h.responseTimeout,
+					},
+					&cli.StringFlag{
+						Name:        "timeout",
+						Usage:       "The maximum time of each download.",
+						Required:    false,
+						Destination: &h.timeout,
+					},
+					&cli.StringSliceFlag{
+						Name:     "header",
+						Aliases:  []string{"H"},
+						Usage:    "A header (Name: value) to send with every request. May be repeated.",
+						Required: false,
+					},
+				},
 			},
 		},
 	}
@@ -58,38 +158,178 @@ func (h *UpdateCommandHandler) Command() *cli.Command {
 
 // UpdateDataSetAction updates the full dataset.
 func (h *UpdateCommandHandler) UpdateDataSetAction(c *cli.Context) error {
-	// Update our data set
-	err := updateDataset(localPath, dataSetURL)
+	cfg, paths, err := loadPaths(c)
+	if err != nil {
+		return err
+	}
+
+	source, err := loadSource(c, cfg)
 	if err != nil {
 		return err
 	}
 
+	if err = h.applyFlags(c, source, cfg); err != nil {
+		return err
+	}
+
+	// Update our data set
+	if err = updateSource(source, cfg, paths); err != nil {
+		return err
+	}
+
+	// Parse the new data set so that later commands load it from the cache
+	if world := cacheDataset(source, cfg, paths); world != nil {
+		recordCounts(source, paths, world)
+	}
+
 	return nil
 }
 
 // MARK: Unexported methods
 
-// updateDataset updates the dataset at the given url and saves it to filepath.
-func updateDataset(filepath string, url string) error {
-	s := NewSpinnerWithTitle("Updating dataset...")
-	s.Start()
-	defer s.Stop()
+// applyFlags overrides the download settings of the configuration with the
+// command's flags.
+func (h *UpdateCommandHandler) applyFlags(c *cli.Context, source sources.DataSource, cfg *config.Config) error {
+	// URLs and checksums given on the command line apply to the only file
+	// of the data source
+	fileOverrides := []struct {
+		flag   string
+		value  string
+		target *map[string]string
+	}{
+		{"url", h.url, &cfg.URLs},
+		{"sha256", h.checksum, &cfg.Checksums},
+	}
+
+	for _, override := range fileOverrides {
+		if len(override.value) == 0 {
+			continue
+		}
+
+		files := source.Files()
+		if len(files) != 1 {
+			return fmt.Errorf("the %s flag needs a single file data source but %s has %d files, use the configuration file instead", override.flag, source.Name(), len(files))
+		}
+
+		values := make(map[string]string)
+		for name, value := range *override.target {
+			values[name] = value
+		}
+		values[files[0].Name] = override.value
+		*override.target = values
+	}
+
+	overrides := []struct {
+		value  string
+		target *string
+	}{
+		{h.mirror, &cfg.Mirror},
+		{h.proxy, &cfg.HTTP.Proxy},
+		{h.caFile, &cfg.HTTP.CAFile},
+		{h.certFile, &cfg.HTTP.CertFile},
+		{h.keyFile, &cfg.HTTP.KeyFile},
+		{h.connectTimeout, &cfg.HTTP.ConnectTimeout},
+		{h.responseTimeout, &cfg.HTTP.ResponseTimeout},
+		{h.timeout, &cfg.HTTP.Timeout},
+	}
+
+	for _, override := range overrides {
+		if len(override.value) > 0 {
+			*override.target = override.value
+		}
+	}
+
+	headers := c.StringSlice("header")
+	if len(headers) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string)
+	for name, value := range cfg.HTTP.Headers {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+
+	for _, header := range headers {
+		i := strings.Index(header, ":")
+		if i <= 0 {
+			return fmt.Errorf("invalid header %q, expected Name: value", header)
+		}
+		merged[http.CanonicalHeaderKey(strings.TrimSpace(header[:i]))] = strings.TrimSpace(header[i+1:])
+	}
+	cfg.HTTP.Headers = merged
+
+	return nil
+}
+
+// updateDataset updates the dataset at the given url and saves it to path.
+// The download is conditional on the validators of the previous download,
+// atomic and resumed if a previous attempt failed. If a checksum is given,
+// downloads whose SHA-256 hash doesn't match it aren't installed. Distinct
+// downloads are archived in the snapshot store and the dataset's provenance
+// is recorded next to it.
+func updateDataset(path string, url string, checksum string, fetcher *fetch.Fetcher, store *snapshots.Store) error {
+	progress := newFetchProgress("Updating dataset...")
+	defer progress.Stop()
+
+	name := filepath.Base(path)
+
+	metadata, err := provenance.Read(path)
+	if err != nil {
+		metadata = &provenance.Metadata{}
+	}
 
-	// Get the data
-	resp, err := http.Get(url)
+	fetcher.Progress = progress
+	fetcher.Verify = nil
+	if len(checksum) > 0 {
+		fetcher.Verify = func(downloadPath string) error {
+			return provenance.VerifyChecksum(name, downloadPath, checksum)
+		}
+	}
+
+	// Validators are only meaningful to the server that issued them, so the
+	// file is downloaded unconditionally when its URL changed
+	validators := metadata.Validators
+	if metadata.URL != url {
+		validators = fetch.Validators{}
+	}
+
+	result, err := fetcher.Fetch(url, path, validators)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	// Create the file
-	out, err := os.Create(filepath)
+	// The existing file wasn't verified if it wasn't modified
+	if result.NotModified && len(checksum) > 0 {
+		if err = provenance.VerifyChecksum(name, path, checksum); err != nil {
+			return err
+		}
+	}
+
+	// Archive the dataset even if it wasn't modified so that the store always
+	// has its current contents. Unchanged contents aren't archived twice.
+	now := time.Now()
+	snapshot, _, err := store.Add(name, path, now)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
 
-	// Write the body to file
-	_, err = io.Copy(out, resp.Body)
-	return err
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !result.NotModified || metadata.URL != url {
+		metadata.URL = url
+		metadata.FetchedAt = now
+		metadata.Validators = result.Validators
+		metadata.Counts = nil
+	}
+
+	metadata.File = name
+	metadata.CheckedAt = now
+	metadata.SHA256 = snapshot.Hash
+	metadata.Size = info.Size()
+	metadata.Snapshot = snapshot.ID()
+
+	return provenance.Write(path, metadata)
 }
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/fetch"
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/snapshots"
+)
+
+func TestUpdateDatasetValidators(t *testing.T) {
+	var conditional bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = len(r.Header.Get("If-None-Match")) > 0 || len(r.Header.Get("If-Modified-Since")) > 0
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v2"`)
+		w.Write([]byte("date,location\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		previousURL string
+		conditional bool
+	}{
+		{"same url", server.URL, true},
+		{"different url", "https://mirror.invalid/full_data.csv", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "update")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "full_data.csv")
+			if err = ioutil.WriteFile(path, []byte("date,location\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err = provenance.Write(path, &provenance.Metadata{
+				File:       "full_data.csv",
+				URL:        test.previousURL,
+				FetchedAt:  time.Now(),
+				Validators: fetch.Validators{ETag: `"v1"`, LastModified: "Sun, 01 Mar 2020 00:00:00 GMT"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f := fetch.NewFetcher()
+			f.MaxRetries = 0
+
+			if err = updateDataset(path, server.URL, "", f, snapshots.NewStore(filepath.Join(dir, "snapshots"))); err != nil {
+				t.Fatal(err)
+			}
+
+			if conditional != test.conditional {
+				t.Errorf("expected a conditional request %v, got %v", test.conditional, conditional)
+			}
+
+			metadata, err := provenance.Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if metadata.URL != server.URL {
+				t.Errorf("expected the URL %q to be recorded, got %q", server.URL, metadata.URL)
+			}
+		})
+	}
+}
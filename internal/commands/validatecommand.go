@@ -0,0 +1,190 @@
+// Package commands conatins the commands for the medina command line application.
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/colinc86/covid-19/internal/provenance"
+	"github.com/colinc86/covid-19/internal/validation"
+	"github.com/urfave/cli/v2"
+)
+
+// ValidateCommandHandler handles validate commands.
+type ValidateCommandHandler struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+
+	// MARK: Private properties
+	strict   bool
+	world    bool
+	severity string
+	format   string
+}
+
+// MARK: Initializers
+
+// NewValidateCommandHandler creates and returns a new validate command
+// handler.
+func NewValidateCommandHandler() *ValidateCommandHandler {
+	return &ValidateCommandHandler{
+		Name:    "validate",
+		Aliases: []string{"v"},
+		Usage:   "Validates the dataset.",
+		Description: `Check every location's records for negative values, decreasing totals,
+		new values that don't match the change of their totals, duplicate or
+		out of order dates and gaps.
+		
+		Examples:
+			# Print a validation report
+			covid19 validate
+			
+			# Only print warnings and errors
+			covid19 validate --severity warning
+			
+			# Also compare the World records with the sum of the locations
+			covid19 validate --world
+			
+			# Exit with a non-zero status if there are warnings or errors
+			covid19 validate --strict`,
+	}
+}
+
+// MARK: Public methods
+
+// Command creates and returns the handler's command.
+func (h *ValidateCommandHandler) Command() *cli.Command {
+	return &cli.Command{
+		Name:        h.Name,
+		Aliases:     h.Aliases,
+		Usage:       h.Usage,
+		Description: h.Description,
+		Action:      h.ValidateAction,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:        "strict",
+				Usage:       "Exit with a non-zero status if there are warnings or errors.",
+				Required:    false,
+				Destination: &h.strict,
+			},
+			&cli.BoolFlag{
+				Name:        "world",
+				Aliases:     []string{"w"},
+				Usage:       "Compare the dataset's World records with the sum of its locations.",
+				Required:    false,
+				Destination: &h.world,
+			},
+			&cli.StringFlag{
+				Name:        "severity",
+				Aliases:     []string{"s"},
+				Usage:       "The minimum severity to report: info, warning or error.",
+				Required:    false,
+				Value:       "info",
+				Destination: &h.severity,
+			},
+			&cli.StringFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "table or json",
+				Required:    false,
+				Value:       "table",
+				Destination: &h.format,
+			},
+		},
+	}
+}
+
+// ValidateAction validates the dataset.
+func (h *ValidateCommandHandler) ValidateAction(c *cli.Context) error {
+	if h.format != "table" && h.format != "json" {
+		return fmt.Errorf("unknown format %q, expected table or json", h.format)
+	}
+
+	severity, err := validation.ParseSeverity(h.severity)
+	if err != nil {
+		return err
+	}
+
+	// Get the world locations
+	world, err := loadWorld(c)
+	if err != nil {
+		return err
+	}
+
+	report := validation.NewValidator().Validate(world)
+	if h.world {
+		report.Add(validation.CheckWorldAggregate(world))
+	}
+
+	// Only report issues with at least the given severity
+	var issues []validation.Issue
+	for _, issue := range report.Issues {
+		if issue.Severity >= severity {
+			issues = append(issues, issue)
+		}
+	}
+
+	if h.format == "json" {
+		dataset, err := loadProvenance(c)
+		if err != nil {
+			return err
+		}
+
+		filtered := *report
+		filtered.Issues = append([]validation.Issue{}, issues...)
+
+		data, err := json.MarshalIndent(struct {
+			validation.Report
+			Dataset []*provenance.Metadata `json:"dataset,omitempty"`
+		}{filtered, dataset}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		h.printReport(report, issues)
+	}
+
+	if h.strict && report.Count(validation.SeverityWarning) > 0 {
+		return cli.Exit(fmt.Sprintf("validation failed with %d error(s) and %d warning(s)", report.CountBySeverity(validation.SeverityError), report.CountBySeverity(validation.SeverityWarning)), 1)
+	}
+
+	return nil
+}
+
+// MARK: Unexported methods
+
+// printReport prints the report's summary and the given issues.
+func (h *ValidateCommandHandler) printReport(report *validation.Report, issues []validation.Issue) {
+	if len(issues) > 0 {
+		fmt.Printf("%-8s %-24s %-32s %-12s %s\n", "Severity", "Rule", "Location", "Date", "Message")
+
+		for _, issue := range issues {
+			fmt.Printf("%-8s %-24s %-32s %-12s %s\n", issue.Severity, issue.Rule, issue.Location, issue.Date.Format("2006-01-02"), issue.Message)
+		}
+
+		fmt.Println()
+	}
+
+	fmt.Printf("Checked %d records of %d locations.\n", report.Records, report.Locations)
+	if report.Synthesized {
+		fmt.Println("The dataset has no World records, they were computed from the locations.")
+	}
+	fmt.Printf("%-26s %d\n", "Errors", report.CountBySeverity(validation.SeverityError))
+	fmt.Printf("%-26s %d\n", "Warnings", report.CountBySeverity(validation.SeverityWarning))
+	fmt.Printf("%-26s %d\n", "Info", report.CountBySeverity(validation.SeverityInfo))
+
+	counts := report.CountByRule()
+	var rules []string
+	for rule := range counts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	for _, rule := range rules {
+		fmt.Printf("  %-24s %d\n", rule, counts[rule])
+	}
+}
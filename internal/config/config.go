@@ -0,0 +1,140 @@
+// Package config contains the configuration and path resolution for the
+// covid19 command line application.
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// The application's directory name used inside of the XDG base directories.
+const appDirectoryName = "covid19"
+
+// The name of the configuration file.
+const configFileName = "config.json"
+
+// ConfigEnvironmentVariable is the environment variable used to override the
+// configuration file's path.
+const ConfigEnvironmentVariable = "COVID19_CONFIG"
+
+// Config types contain the user's configuration.
+type Config struct {
+
+	// The directory that datasets are stored in.
+	DataDir string `json:"dataDir,omitempty"`
+
+	// The directory that derived, disposable files are stored in.
+	CacheDir string `json:"cacheDir,omitempty"`
+
+	// The data source used when none is given on the command line.
+	Source string `json:"source,omitempty"`
+
+	// Additional alternative names of CSV columns keyed by canonical column
+	// name, e.g. "location": ["Country"].
+	ColumnAliases map[string][]string `json:"columnAliases,omitempty"`
+
+	// Whether or not rows that can't be parsed are skipped with a warning
+	// instead of failing the command.
+	Lenient bool `json:"lenient,omitempty"`
+
+	// The base URL of a mirror that datasets are downloaded from in place of
+	// their data source's URLs. Files are requested by name from the mirror.
+	Mirror string `json:"mirror,omitempty"`
+
+	// The URLs that datasets are downloaded from keyed by file name. URLs
+	// take precedence over the mirror.
+	URLs map[string]string `json:"urls,omitempty"`
+
+	// The pinned SHA-256 checksums of datasets keyed by file name. Datasets
+	// whose contents don't match aren't installed.
+	Checksums map[string]string `json:"checksums,omitempty"`
+
+	// The settings of the HTTP client that downloads datasets.
+	HTTP HTTPConfig `json:"http,omitempty"`
+
+	// The path of a CSV file of location metadata whose rows are merged over
+	// the built-in table's.
+	LocationMetadata string `json:"locationMetadata,omitempty"`
+
+	// The names or ISO codes of the locations of user-defined groups keyed
+	// by group name. Groups replace the built-in groups with the same name
+	// and take precedence over locations of the dataset with the same name.
+	Groups map[string][]string `json:"groups,omitempty"`
+}
+
+// HTTPConfig types contain the settings of the HTTP client that downloads
+// datasets.
+type HTTPConfig struct {
+
+	// The URL of the proxy for HTTP and HTTPS requests. Defaults to the
+	// HTTP_PROXY and HTTPS_PROXY environment variables.
+	Proxy string `json:"proxy,omitempty"`
+
+	// The path of a PEM file of additional certificate authorities to trust.
+	CAFile string `json:"caFile,omitempty"`
+
+	// The paths of the PEM encoded client certificate and its private key.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+
+	// The maximum time spent connecting to a server, e.g. "10s".
+	ConnectTimeout string `json:"connectTimeout,omitempty"`
+
+	// The maximum time spent waiting for a server to respond to a request.
+	ResponseTimeout string `json:"responseTimeout,omitempty"`
+
+	// The maximum time of a whole download.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Additional headers sent with every request keyed by name.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// MARK: Initializers
+
+// Load reads the configuration file at the given path. If path is empty, the
+// default configuration path is used. A missing configuration file is not an
+// error and results in an empty configuration.
+func Load(path string) (*Config, error) {
+	if len(path) == 0 {
+		path = ConfigPath()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// MARK: Exported functions
+
+// ConfigPath returns the path of the configuration file. The path is taken
+// from the COVID19_CONFIG environment variable, then $XDG_CONFIG_HOME and
+// finally ~/.config.
+func ConfigPath() string {
+	if path := os.Getenv(ConfigEnvironmentVariable); len(path) > 0 {
+		return path
+	}
+
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, appDirectoryName, configFileName)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", appDirectoryName, configFileName)
+	}
+
+	return filepath.Join(os.TempDir(), appDirectoryName, configFileName)
+}
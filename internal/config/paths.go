@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DataDirEnvironmentVariable is the environment variable used to override
+// the data directory.
+const DataDirEnvironmentVariable = "COVID19_DATA_DIR"
+
+// CacheDirEnvironmentVariable is the environment variable used to override
+// the cache directory.
+const CacheDirEnvironmentVariable = "COVID19_CACHE_DIR"
+
+// Paths types contain the resolved locations of the application's files and
+// where each of them came from.
+type Paths struct {
+
+	// The configuration file's path.
+	ConfigFile string
+
+	// The directory that datasets are stored in.
+	DataDir string
+
+	// Where the data directory was resolved from.
+	DataDirSource string
+
+	// The directory that derived, disposable files are stored in.
+	CacheDir string
+
+	// Where the cache directory was resolved from.
+	CacheDirSource string
+}
+
+// MARK: Initializers
+
+// ResolvePaths resolves the application's paths. The data directory is taken
+// from, in order, dataDir (typically a command line flag), the
+// COVID19_DATA_DIR environment variable, the configuration file,
+// $XDG_DATA_HOME, $XDG_CACHE_HOME and finally ~/.local/share.
+func ResolvePaths(dataDir string, configFile string, config *Config) *Paths {
+	if len(configFile) == 0 {
+		configFile = ConfigPath()
+	}
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	paths := &Paths{ConfigFile: configFile}
+	paths.DataDir, paths.DataDirSource = resolveDataDir(dataDir, config)
+	paths.CacheDir, paths.CacheDirSource = resolveCacheDir(config)
+	return paths
+}
+
+// MARK: Exported methods
+
+// DataPath returns the path of the file with the given name inside of the
+// data directory.
+func (p Paths) DataPath(name string) string {
+	return filepath.Join(p.DataDir, name)
+}
+
+// SnapshotDir returns the directory that dataset snapshots are archived in.
+func (p Paths) SnapshotDir() string {
+	return filepath.Join(p.DataDir, "snapshots")
+}
+
+// EnsureDataDir creates the data directory if it does not exist.
+func (p Paths) EnsureDataDir() error {
+	return os.MkdirAll(p.DataDir, 0755)
+}
+
+// CachePath returns the path of the file with the given name inside of the
+// cache directory.
+func (p Paths) CachePath(name string) string {
+	return filepath.Join(p.CacheDir, name)
+}
+
+// EnsureCacheDir creates the cache directory if it does not exist.
+func (p Paths) EnsureCacheDir() error {
+	return os.MkdirAll(p.CacheDir, 0755)
+}
+
+// MARK: Unexported functions
+
+// resolveDataDir returns the data directory and its source.
+func resolveDataDir(dataDir string, config *Config) (string, string) {
+	if len(dataDir) > 0 {
+		return dataDir, "flag"
+	}
+
+	if dir := os.Getenv(DataDirEnvironmentVariable); len(dir) > 0 {
+		return dir, DataDirEnvironmentVariable
+	}
+
+	if len(config.DataDir) > 0 {
+		return config.DataDir, "config"
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, appDirectoryName), "XDG_DATA_HOME"
+	}
+
+	if dir := os.Getenv("XDG_CACHE_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, appDirectoryName), "XDG_CACHE_HOME"
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", appDirectoryName), "default"
+	}
+
+	return filepath.Join(os.TempDir(), appDirectoryName), "default"
+}
+
+// resolveCacheDir returns the cache directory and its source.
+func resolveCacheDir(config *Config) (string, string) {
+	if dir := os.Getenv(CacheDirEnvironmentVariable); len(dir) > 0 {
+		return dir, CacheDirEnvironmentVariable
+	}
+
+	if len(config.CacheDir) > 0 {
+		return config.CacheDir, "config"
+	}
+
+	if dir := os.Getenv("XDG_CACHE_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, appDirectoryName), "XDG_CACHE_HOME"
+	}
+
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, appDirectoryName), "default"
+	}
+
+	return filepath.Join(os.TempDir(), appDirectoryName, "cache"), "default"
+}
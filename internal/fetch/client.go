@@ -0,0 +1,126 @@
+package fetch
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientOptions types contain the network settings of the HTTP client used to
+// fetch files.
+type ClientOptions struct {
+
+	// The URL of the proxy that HTTP and HTTPS requests are sent through. An
+	// empty proxy uses the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	Proxy string
+
+	// The path of a PEM file of certificate authorities that are trusted in
+	// addition to the system's.
+	CAFile string
+
+	// The paths of the PEM encoded certificate and private key presented to
+	// servers that require client certificates. An empty key file reads the
+	// key from the certificate file.
+	CertFile string
+	KeyFile  string
+
+	// The maximum time spent connecting to a server, including the TLS
+	// handshake. Zero uses the default of 30 seconds.
+	ConnectTimeout time.Duration
+
+	// The maximum time spent waiting for a server's response headers after
+	// sending a request. Zero waits indefinitely.
+	ResponseTimeout time.Duration
+
+	// The maximum time of a request, including reading the body. Zero waits
+	// indefinitely.
+	Timeout time.Duration
+}
+
+// MARK: Initializers
+
+// NewClient creates and returns a new HTTP client with the given options.
+func NewClient(options ClientOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(options.Proxy) > 0 {
+		proxy, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if options.ConnectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   options.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = options.ConnectTimeout
+	}
+
+	transport.ResponseHeaderTimeout = options.ResponseTimeout
+
+	if len(options.CAFile) > 0 || len(options.CertFile) > 0 || len(options.KeyFile) > 0 {
+		tlsConfig, err := newTLSConfig(options)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   options.Timeout,
+	}, nil
+}
+
+// MARK: Unexported functions
+
+// newTLSConfig creates and returns the TLS configuration of the options'
+// certificates.
+func newTLSConfig(options ClientOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if len(options.CAFile) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		data, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(options.CertFile) > 0 {
+		keyFile := options.KeyFile
+		if len(keyFile) == 0 {
+			keyFile = options.CertFile
+		}
+
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	} else if len(options.KeyFile) > 0 {
+		return nil, errors.New("a client key was given without a certificate")
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,412 @@
+// Package fetch contains the HTTP download logic used to update datasets.
+package fetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// The suffix of in-progress downloads.
+const partialSuffix = ".part"
+
+// The suffix of the validators file of in-progress downloads.
+const partialValidatorsSuffix = ".part.json"
+
+// errUnexpectedRange is returned when a resumed download's response doesn't
+// continue the partial file. The partial file is discarded, so a retry
+// starts over.
+var errUnexpectedRange = errors.New("server returned an unexpected content range")
+
+// Validators contain the HTTP cache validators of a downloaded file.
+type Validators struct {
+
+	// The ETag header of the response.
+	ETag string `json:"etag,omitempty"`
+
+	// The Last-Modified header of the response.
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// Progress types receive download progress.
+type Progress interface {
+
+	// Start is called before the body is read with the total number of bytes
+	// that will be written to the file, or -1 if unknown, and the number of
+	// bytes that have already been written by a previous attempt.
+	Start(total int64, written int64)
+
+	// Add is called each time n bytes have been written.
+	Add(n int)
+
+	// Finish is called after the body has been read.
+	Finish()
+}
+
+// Result types contain the result of a fetch.
+type Result struct {
+
+	// Whether or not the server reported that the file has not been
+	// modified since it was last fetched.
+	NotModified bool
+
+	// The validators of the fetched file.
+	Validators Validators
+
+	// The number of bytes received by this fetch.
+	Received int64
+
+	// Whether or not the fetch resumed a previous, partial download.
+	Resumed bool
+}
+
+// Fetcher types download files.
+type Fetcher struct {
+
+	// The HTTP client used to perform requests.
+	Client *http.Client
+
+	// Additional headers sent with every request.
+	Header http.Header
+
+	// The maximum number of times a failed request is retried.
+	MaxRetries int
+
+	// The delay before the first retry. The delay doubles with every
+	// subsequent retry.
+	InitialBackoff time.Duration
+
+	// The maximum delay between retries.
+	MaxBackoff time.Duration
+
+	// An optional progress receiver.
+	Progress Progress
+
+	// An optional check of each completed download. A download only replaces
+	// the file if the check returns nil, and isn't retried if it fails.
+	Verify func(path string) error
+}
+
+// statusError types are returned for unexpected HTTP status codes.
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+// verifyError types are returned for downloads that failed verification.
+type verifyError struct {
+	err error
+}
+
+// partialDownload types contain the URL and validators of a partial
+// download, which are stored next to it so that it can be resumed.
+type partialDownload struct {
+	URL string `json:"url"`
+	Validators
+}
+
+// progressWriter types report the bytes written to a writer.
+type progressWriter struct {
+	writer   io.Writer
+	progress Progress
+}
+
+// MARK: Initializers
+
+// NewFetcher creates and returns a new fetcher with default settings.
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client:         http.DefaultClient,
+		MaxRetries:     4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// MARK: Exported methods
+
+// Fetch downloads the file at url to path.
+//
+// If validators are given and path exists, the request is made conditional
+// and the file is left untouched when the server responds with 304 Not
+// Modified. The body is written to a temporary file next to path which is
+// only renamed over path once the download completes, so a failed download
+// never corrupts an existing file. Failed downloads are retried with
+// exponential backoff and resumed with a Range request when the server
+// supports it.
+func (f *Fetcher) Fetch(url string, path string, validators Validators) (*Result, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		validators = Validators{}
+	}
+
+	backoff := f.InitialBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var result *Result
+		result, err = f.attempt(url, path, validators)
+		if err == nil {
+			return result, nil
+		}
+
+		if !retryable(err) || attempt >= f.MaxRetries {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > f.MaxBackoff {
+			backoff = f.MaxBackoff
+		}
+	}
+
+	return nil, err
+}
+
+// MARK: Unexported methods
+
+// attempt performs a single request.
+func (f *Fetcher) attempt(url string, path string, validators Validators) (*Result, error) {
+	partialPath := path + partialSuffix
+	partialValidatorsPath := path + partialValidatorsSuffix
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range f.Header {
+		request.Header[name] = values
+	}
+
+	// Resume a previous download of the same URL if we know what it was a
+	// download of, otherwise make the request conditional.
+	var offset int64
+	partial, partialErr := readPartialDownload(partialValidatorsPath)
+	partialValidators := partial.Validators
+	info, statErr := os.Stat(partialPath)
+	if partialErr == nil && statErr == nil && info.Size() > 0 && partial.URL == url {
+		if len(partialValidators.ETag) > 0 {
+			request.Header.Set("If-Range", partialValidators.ETag)
+		} else {
+			request.Header.Set("If-Range", partialValidators.LastModified)
+		}
+
+		offset = info.Size()
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else {
+		if len(validators.ETag) > 0 {
+			request.Header.Set("If-None-Match", validators.ETag)
+		}
+
+		if len(validators.LastModified) > 0 {
+			request.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	response, err := f.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseValidators := Validators{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+
+	var out *os.File
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		return &Result{NotModified: true, Validators: validators}, nil
+	case http.StatusOK:
+		offset = 0
+		out, err = os.Create(partialPath)
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(response.Header.Get("Content-Range")); !ok || start != offset {
+			discardPartial(path)
+			return nil, errUnexpectedRange
+		}
+		out, err = os.OpenFile(partialPath, os.O_WRONLY|os.O_APPEND, 0644)
+	case http.StatusRequestedRangeNotSatisfiable:
+		discardPartial(path)
+		return nil, &statusError{url: url, status: response.Status, code: response.StatusCode}
+	default:
+		return nil, &statusError{url: url, status: response.Status, code: response.StatusCode}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writePartialDownload(partialValidatorsPath, partialDownload{URL: url, Validators: responseValidators}); err != nil {
+		out.Close()
+		return nil, err
+	}
+
+	total := int64(-1)
+	if response.ContentLength >= 0 {
+		total = offset + response.ContentLength
+	}
+
+	received, err := f.copy(out, response.Body, total, offset)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.ContentLength >= 0 && received != response.ContentLength {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	if f.Verify != nil {
+		if err = f.Verify(partialPath); err != nil {
+			discardPartial(path)
+			return nil, &verifyError{err: err}
+		}
+	}
+
+	if err = os.Rename(partialPath, path); err != nil {
+		return nil, err
+	}
+
+	os.Remove(partialValidatorsPath)
+
+	return &Result{
+		Validators: responseValidators,
+		Received:   received,
+		Resumed:    offset > 0,
+	}, nil
+}
+
+// copy copies the body to out, syncs out and reports progress.
+func (f *Fetcher) copy(out *os.File, body io.Reader, total int64, offset int64) (int64, error) {
+	var writer io.Writer = out
+	if f.Progress != nil {
+		f.Progress.Start(total, offset)
+		defer f.Progress.Finish()
+		writer = &progressWriter{writer: out, progress: f.Progress}
+	}
+
+	received, err := io.Copy(writer, body)
+	if err != nil {
+		return received, err
+	}
+
+	return received, out.Sync()
+}
+
+// MARK: Error interface methods
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected response %s from %s", e.status, e.url)
+}
+
+func (e *verifyError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the failed verification.
+func (e *verifyError) Unwrap() error {
+	return e.err
+}
+
+// MARK: Writer interface methods
+
+// Write writes p to the underlying writer and reports its progress.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.progress.Add(n)
+	return n, err
+}
+
+// MARK: Unexported functions
+
+// retryable returns whether or not the error may succeed on a retry. Only
+// transient failures are retried: timeouts, connections that were reset or
+// closed early, truncated bodies and server-side status codes. Everything
+// else, such as malformed URLs, canceled requests, TLS and file errors, fails
+// immediately.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusRequestTimeout ||
+			se.code == http.StatusTooManyRequests ||
+			se.code == http.StatusRequestedRangeNotSatisfiable ||
+			se.code >= 500
+	}
+
+	if errors.Is(err, errUnexpectedRange) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var de *net.DNSError
+	if errors.As(err, &de) {
+		return de.IsTimeout || de.IsTemporary
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// contentRangeStart parses the first byte position of a Content-Range header.
+func contentRangeStart(header string) (int64, bool) {
+	var start, end int64
+	var size string
+	if _, err := fmt.Sscanf(header, "bytes %d-%d/%s", &start, &end, &size); err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// discardPartial removes the partial download of path.
+func discardPartial(path string) {
+	os.Remove(path + partialSuffix)
+	os.Remove(path + partialValidatorsSuffix)
+}
+
+// readPartialDownload reads a partial download's URL and validators from the
+// JSON file at path.
+func readPartialDownload(path string) (partialDownload, error) {
+	var partial partialDownload
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return partial, err
+	}
+
+	err = json.Unmarshal(data, &partial)
+	if err == nil && len(partial.ETag) == 0 && len(partial.LastModified) == 0 {
+		err = errors.New("no validators")
+	}
+	return partial, err
+}
+
+// writePartialDownload writes a partial download's URL and validators as
+// JSON to the file at path.
+func writePartialDownload(path string, partial partialDownload) error {
+	data, err := json.Marshal(partial)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
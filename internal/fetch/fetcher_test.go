@@ -0,0 +1,263 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// timeoutError types are net errors that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &statusError{code: http.StatusServiceUnavailable}, true},
+		{"too many requests", &statusError{code: http.StatusTooManyRequests}, true},
+		{"not found", &statusError{code: http.StatusNotFound}, false},
+		{"truncated body", io.ErrUnexpectedEOF, true},
+		{"unexpected range", errUnexpectedRange, true},
+		{"timeout", &url.Error{Op: "Get", URL: "http://example.com", Err: timeoutError{}}, true},
+		{"connection reset", &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, true},
+		{"connection refused", &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, false},
+		{"canceled", &url.Error{Op: "Get", URL: "http://example.com", Err: context.Canceled}, false},
+		{"malformed url", &url.Error{Op: "parse", URL: "::", Err: errors.New("missing protocol scheme")}, false},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}, false},
+		{"file error", &os.PathError{Op: "open", Path: "/nonexistent", Err: syscall.ENOENT}, false},
+		{"failed verification", &verifyError{err: errors.New("checksum mismatch")}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := retryable(test.err); got != test.want {
+				t.Errorf("retryable(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
+
+func TestFetchConditional(t *testing.T) {
+	content := []byte("date,location\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(tempDir(t), "data.csv")
+	f := testFetcher()
+
+	result, err := f.Fetch(server.URL, path, Validators{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.NotModified || result.Validators.ETag != `"v1"` || result.Received != int64(len(content)) {
+		t.Fatalf("unexpected first result %+v", result)
+	}
+
+	result, err = f.Fetch(server.URL, path, result.Validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.NotModified {
+		t.Errorf("expected the second fetch to be not modified, got %+v", result)
+	}
+	assertFile(t, path, content)
+}
+
+func TestFetchResumes(t *testing.T) {
+	content := []byte(strings.Repeat("2020-01-01,World\n", 64))
+	offset := 100
+
+	tests := []struct {
+		name    string
+		url     string
+		resumed bool
+	}{
+		{"same url", "", true},
+		{"different url", "http://mirror.invalid/data.csv", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ranges int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(r.Header.Get("Range")) > 0 {
+					atomic.AddInt32(&ranges, 1)
+				}
+
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "data.csv", time.Time{}, bytes.NewReader(content))
+			}))
+			defer server.Close()
+
+			partialURL := test.url
+			if len(partialURL) == 0 {
+				partialURL = server.URL
+			}
+
+			path := filepath.Join(tempDir(t), "data.csv")
+			if err := ioutil.WriteFile(path+partialSuffix, content[:offset], 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := writePartialDownload(path+partialValidatorsSuffix, partialDownload{URL: partialURL, Validators: Validators{ETag: `"v1"`}}); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := testFetcher().Fetch(server.URL, path, Validators{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			received := int64(len(content))
+			if test.resumed {
+				received -= int64(offset)
+			}
+
+			if result.Resumed != test.resumed || result.Received != received || (atomic.LoadInt32(&ranges) == 1) != test.resumed {
+				t.Errorf("expected resumed %v and %d bytes, got %+v", test.resumed, received, result)
+			}
+			assertFile(t, path, content)
+
+			if _, err := os.Stat(path + partialSuffix); !os.IsNotExist(err) {
+				t.Errorf("expected the partial file to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		status   int
+		requests int32
+		fails    bool
+	}{
+		{"transient failure", 2, http.StatusServiceUnavailable, 3, false},
+		{"too many failures", 10, http.StatusBadGateway, 3, true},
+		{"permanent failure", 10, http.StatusNotFound, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= test.failures {
+					w.WriteHeader(test.status)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			f := testFetcher()
+			f.MaxRetries = 2
+
+			_, err := f.Fetch(server.URL, filepath.Join(tempDir(t), "data.csv"), Validators{})
+			if (err != nil) != test.fails {
+				t.Errorf("expected failure %v, got %v", test.fails, err)
+			}
+
+			if got := atomic.LoadInt32(&requests); got != test.requests {
+				t.Errorf("expected %d requests, got %d", test.requests, got)
+			}
+		})
+	}
+}
+
+func TestFetchKeepsFileOnFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"error status", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}},
+		{"truncated body", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte("date,loc"))
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			path := filepath.Join(tempDir(t), "data.csv")
+			content := []byte("date,location\n2020-03-01,Italy\n")
+			if err := ioutil.WriteFile(path, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			f := testFetcher()
+			f.MaxRetries = 0
+
+			if _, err := f.Fetch(server.URL, path, Validators{}); err == nil {
+				t.Fatal("expected the fetch to fail")
+			}
+			assertFile(t, path, content)
+		})
+	}
+}
+
+// testFetcher returns a fetcher that doesn't wait between retries.
+func testFetcher() *Fetcher {
+	f := NewFetcher()
+	f.InitialBackoff = time.Millisecond
+	f.MaxBackoff = time.Millisecond
+	return f
+}
+
+// tempDir creates a temporary directory that is removed when the test ends.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "fetch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// assertFile fails the test if the file at path doesn't have the content.
+func assertFile(t *testing.T, path string, content []byte) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, content) {
+		t.Errorf("expected %q, got %q", content, data)
+	}
+}
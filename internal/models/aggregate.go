@@ -0,0 +1,131 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// The lower case names of locations that datasets list alongside countries
+// but that are made up of other locations, such as continents, income groups
+// and the nations of the United Kingdom. Their values are already counted by
+// their members.
+var aggregateLocationNames = map[string]bool{
+	"world":                             true,
+	"africa":                            true,
+	"asia":                              true,
+	"europe":                            true,
+	"european union":                    true,
+	"european union (27)":               true,
+	"north america":                     true,
+	"south america":                     true,
+	"oceania":                           true,
+	"international":                     true,
+	"high income":                       true,
+	"upper middle income":               true,
+	"lower middle income":               true,
+	"low income":                        true,
+	"asia excl. china":                  true,
+	"world excl. china":                 true,
+	"world excl. china and south korea": true,
+	"world excl. china, south korea, japan and singapore": true,
+	"england":          true,
+	"scotland":         true,
+	"wales":            true,
+	"northern ireland": true,
+}
+
+// IsAggregateLocation returns whether or not the location with the given
+// case-insensitive name is made up of other locations of a dataset, such as a
+// continent or an income group.
+func IsAggregateLocation(name string) bool {
+	return aggregateLocationNames[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// AggregateRecords combines the records of the given locations into a single
+// series of records with the given location name by summing the values of
+// every location for each date.
+//
+// Locations that have not reported a cumulative metric on a date contribute
+// the last value they reported. Other metrics are summed over the locations
+// that reported them. A metric is missing from an aggregate record when no
+// location contributed to it.
+func AggregateRecords(name string, locations []*Location) []*COVRecord {
+	dateSet := make(map[time.Time]bool)
+	for _, l := range locations {
+		for _, r := range l.Records {
+			dateSet[r.Date] = true
+		}
+	}
+
+	var dates []time.Time
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	indices := make([]int, len(locations))
+	last := make([][]int, len(locations))
+	reported := make([]MetricSet, len(locations))
+	for i := range locations {
+		last[i] = make([]int, len(metrics))
+	}
+
+	records := make([]*COVRecord, 0, len(dates))
+
+	for _, date := range dates {
+		values := make([]int, len(metrics))
+		var contributed MetricSet
+
+		for i, l := range locations {
+			for indices[i] < len(l.Records) && l.Records[indices[i]].Date.Before(date) {
+				indices[i]++
+			}
+
+			var r *COVRecord
+			if indices[i] < len(l.Records) && l.Records[indices[i]].Date.Equal(date) {
+				r = l.Records[indices[i]]
+			}
+
+			for j := range metrics {
+				metric := Metric(j)
+				if r != nil && !r.IsMissing(metric) {
+					last[i][j] = r.Value(metric)
+					reported[i] = reported[i].With(metric)
+					values[j] += last[i][j]
+					contributed = contributed.With(metric)
+				} else if metric.Cumulative() && reported[i].Has(metric) {
+					values[j] += last[i][j]
+					contributed = contributed.With(metric)
+				}
+			}
+		}
+
+		record := &COVRecord{Date: date, Location: name}
+		for j, value := range values {
+			if contributed.Has(Metric(j)) {
+				record.SetValue(Metric(j), value)
+			} else {
+				record.SetMissing(Metric(j))
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// MARK: Unexported functions
+
+// memberLocations returns the locations that aren't aggregates of other
+// locations.
+func memberLocations(locations []*Location) []*Location {
+	members := make([]*Location, 0, len(locations))
+	for _, l := range locations {
+		if !IsAggregateLocation(l.Name) {
+			members = append(members, l)
+		}
+	}
+	return members
+}
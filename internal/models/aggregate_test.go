@@ -0,0 +1,88 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsAggregateLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Europe", true},
+		{"european union", true},
+		{"High income", true},
+		{"International", true},
+		{" World ", true},
+		{"Italy", false},
+		{"Kosovo", false},
+		{"Northern Cyprus", false},
+	}
+
+	for _, test := range tests {
+		if got := IsAggregateLocation(test.name); got != test.want {
+			t.Errorf("IsAggregateLocation(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCheckAggregateExcludesAggregates(t *testing.T) {
+	path := writeDataset(t, `date,location,new_cases,new_deaths,total_cases,total_deaths
+2020-03-01,Europe,15,3,40,5
+2020-03-01,France,5,1,10,2
+2020-03-01,High income,15,3,40,5
+2020-03-01,Italy,10,2,30,3
+2020-03-01,World,15,3,40,5
+`)
+
+	world, err := NewWorldFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changes := world.CheckAggregate(); len(changes) > 0 {
+		t.Errorf("expected no differences, got %d: %+v", len(changes), changes)
+	}
+}
+
+func TestSynthesizedWorldExcludesAggregates(t *testing.T) {
+	path := writeDataset(t, `date,location,new_cases,new_deaths,total_cases,total_deaths
+2020-03-01,Europe,15,3,40,5
+2020-03-01,France,5,1,10,2
+2020-03-01,Italy,10,2,30,3
+`)
+
+	world, err := NewWorldFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !world.Synthesized || len(world.Records) != 1 {
+		t.Fatalf("expected one synthesized world record, got %d", len(world.Records))
+	}
+
+	if cases := world.Records[0].TotalCases; cases != 40 {
+		t.Errorf("expected 40 total cases, got %d", cases)
+	}
+}
+
+// writeDataset writes the CSV dataset to a temporary file that is removed
+// when the test ends, and returns its path.
+func writeDataset(t testing.TB, data string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "full_data.csv")
+	if err = ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// The common alternative names of locations. The first name of each set is
+// the name Our World in Data uses, and the others are abbreviations and the
+// names used by other datasets, such as Johns Hopkins University's.
+var locationAliases = [][]string{
+	{"United States", "US", "USA", "U.S.", "United States of America", "America"},
+	{"United Kingdom", "UK", "U.K.", "Great Britain", "Britain"},
+	{"United Arab Emirates", "UAE"},
+	{"Czechia", "Czech Republic"},
+	{"South Korea", "Korea, South", "Republic of Korea", "Korea"},
+	{"North Korea", "Korea, North"},
+	{"Taiwan", "Taiwan*"},
+	{"Myanmar", "Burma"},
+	{"Cote d'Ivoire", "Ivory Coast"},
+	{"Democratic Republic of Congo", "Congo (Kinshasa)", "DR Congo", "DRC"},
+	{"Congo", "Congo (Brazzaville)", "Republic of the Congo"},
+	{"Cape Verde", "Cabo Verde"},
+	{"Eswatini", "Swaziland"},
+	{"North Macedonia", "Macedonia"},
+	{"Timor", "Timor-Leste", "East Timor"},
+	{"Vatican", "Holy See"},
+	{"Palestine", "West Bank and Gaza"},
+	{"Micronesia (country)", "Micronesia"},
+	{"Faeroe Islands", "Faroe Islands"},
+	{"Russia", "Russian Federation"},
+	{"Netherlands", "Holland"},
+}
+
+// The alias sets keyed by each of their lower case names.
+var locationAliasIndex = newLocationAliasIndex()
+
+// MARK: Exported functions
+
+// LocationAliases returns the names that refer to the same location as name,
+// including name. Names are compared case-insensitively.
+func LocationAliases(name string) []string {
+	if aliases, ok := locationAliasIndex[normalizeLocationName(name)]; ok {
+		return aliases
+	}
+	return []string{name}
+}
+
+// SuggestLocations returns up to n of the candidates that are closest to
+// name, closest first. Candidates that start with name, or that are a few
+// edits away from it, are suggested.
+func SuggestLocations(name string, candidates []string, n int) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	name = normalizeLocationName(name)
+	if len(name) == 0 {
+		return nil
+	}
+
+	maxDistance := len(name) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	seen := make(map[string]bool)
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		normalized := normalizeLocationName(candidate)
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+
+		distance := editDistance(name, normalized)
+		if strings.HasPrefix(normalized, name) && distance > maxDistance {
+			distance = maxDistance
+		}
+
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: candidate, distance: distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(suggestions) && i < n; i++ {
+		names = append(names, suggestions[i].name)
+	}
+	return names
+}
+
+// MARK: Unexported functions
+
+// newLocationAliasIndex creates and returns the alias sets keyed by each of
+// their lower case names.
+func newLocationAliasIndex() map[string][]string {
+	index := make(map[string][]string)
+	for _, aliases := range locationAliases {
+		for _, alias := range aliases {
+			index[normalizeLocationName(alias)] = aliases
+		}
+	}
+	return index
+}
+
+// normalizeLocationName returns the key that a location's name is compared
+// by.
+func normalizeLocationName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ar := []rune(a)
+	br := []rune(b)
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
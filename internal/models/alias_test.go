@@ -0,0 +1,121 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "italy", 5},
+		{"italy", "", 5},
+		{"italy", "italy", 0},
+		{"itly", "italy", 1},
+		{"itlay", "italy", 2},
+		{"spain", "spian", 2},
+		{"kitten", "sitting", 3},
+		{"côte", "cote", 1},
+	}
+
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSuggestLocations(t *testing.T) {
+	candidates := []string{"Italy", "Iceland", "India", "Indonesia", "Ireland", "Israel", "United Kingdom", "United States", "ITALY"}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"Itly", 3, []string{"Italy"}},
+		{"united", 3, []string{"United Kingdom", "United States"}},
+		{"Inda", 3, []string{"India"}},
+		{"Indai", 3, nil},
+		{"Ind", 1, []string{"India"}},
+		{"Irland", 3, []string{"Ireland", "Iceland"}},
+		{"Zzzzzz", 3, nil},
+		{"  ", 3, nil},
+	}
+
+	for _, test := range tests {
+		if got := SuggestLocations(test.name, candidates, test.n); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SuggestLocations(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLocationAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		includes string
+	}{
+		{"US", "United States"},
+		{"united states of america", "USA"},
+		{"Korea, South", "South Korea"},
+		{"Burma", "Myanmar"},
+		{"Atlantis", "Atlantis"},
+	}
+
+	for _, test := range tests {
+		found := false
+		for _, alias := range LocationAliases(test.name) {
+			found = found || alias == test.includes
+		}
+
+		if !found {
+			t.Errorf("expected the aliases of %q to include %q, got %q", test.name, test.includes, LocationAliases(test.name))
+		}
+	}
+}
+
+func TestWorldLocation(t *testing.T) {
+	world := NewWorld([]*Location{
+		NewLocation("Italy", nil),
+		NewLocation("United States", nil),
+		NewLocation("Korea, South", nil),
+		NewLocation("Georgia", nil),
+	}, nil)
+	world.JoinLocationInfo(NewLocationTable([]*LocationInfo{
+		{Name: "Italy", ISO2: "IT", ISO3: "ITA"},
+		{Name: "United States", ISO2: "US", ISO3: "USA"},
+		{Name: "South Korea", ISO2: "KR", ISO3: "KOR"},
+		{Name: "Georgia", ISO2: "GE", ISO3: "GEO"},
+		{Name: "Germany", ISO2: "DE", ISO3: "DEU"},
+	}))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Italy", "Italy"},
+		{" italy ", "Italy"},
+		{"ITA", "Italy"},
+		{"it", "Italy"},
+		{"USA", "United States"},
+		{"United States of America", "United States"},
+		{"South Korea", "Korea, South"},
+		{"KOR", "Korea, South"},
+		{"GEO", "Georgia"},
+		{"DEU", ""},
+		{"Atlantis", ""},
+	}
+
+	for _, test := range tests {
+		l := world.Location(test.name)
+		switch {
+		case l == nil && len(test.want) > 0:
+			t.Errorf("expected %q to resolve to %q, got nil", test.name, test.want)
+		case l != nil && l.Name != test.want:
+			t.Errorf("expected %q to resolve to %q, got %q", test.name, test.want, l.Name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
 )
@@ -27,58 +28,216 @@ type COVRecord struct {
 
 	// The total number of deaths for this location.
 	TotalDeaths int
+
+	// The number of new tests.
+	NewTests int
+
+	// The total number of tests for this location.
+	TotalTests int
+
+	// The number of patients in hospital.
+	HospPatients int
+
+	// The number of patients in intensive care.
+	ICUPatients int
+
+	// The number of new vaccination doses administered.
+	NewVaccinations int
+
+	// The total number of vaccination doses administered.
+	TotalVaccinations int
+
+	// The number of people that received at least one vaccine dose.
+	PeopleVaccinated int
+
+	// The number of people that received all doses of a vaccine.
+	PeopleFullyVaccinated int
+
+	// The population of this location.
+	Population int
+
+	// The metrics that were not reported. Their values are zero.
+	Missing MetricSet
 }
 
 // MARK: Initializers
 
 // NewCOVRecord creates and returns a new COVID-19 record with the given row
-// from a CSV dataset.
-func NewCOVRecord(record []string) (*COVRecord, error) {
-	if len(record) != 6 {
-		return nil, fmt.Errorf("expected 6 columns but found %d", len(record))
+// from a CSV dataset. Fields are read from the columns mapped by header and
+// metrics with empty fields or whose columns are not in the header are
+// marked as missing. Errors are returned as *FieldError.
+func NewCOVRecord(header *Header, record []string) (*COVRecord, error) {
+	dateField := header.value(record, "date")
+	date, err := time.Parse("2006-01-02", dateField)
+	if err != nil {
+		return nil, &FieldError{Column: "date", Value: dateField, Err: errors.New("expected a date formatted as YYYY-MM-DD")}
 	}
 
-	if record[0] == "date" {
-		return nil, errors.New("header")
+	location := header.value(record, "location")
+	if len(location) == 0 {
+		return nil, &FieldError{Column: "location", Value: location, Err: errors.New("empty location")}
 	}
 
-	date, err := time.Parse("2006-01-02", record[0])
-	if err != nil {
-		return nil, err
+	covRecord := &COVRecord{
+		Date:     date,
+		Location: location,
+	}
+
+	for i := range metrics {
+		metric := Metric(i)
+		field := header.value(record, metric.Column())
+		if len(field) == 0 {
+			covRecord.SetMissing(metric)
+			continue
+		}
+
+		value, err := parseCount(field)
+		if err != nil {
+			return nil, &FieldError{Column: metric.Column(), Value: field, Err: errors.New("expected a number")}
+		}
+
+		covRecord.SetValue(metric, value)
+	}
+
+	return covRecord, nil
+}
+
+// MARK: Exported methods
+
+// Value returns the value of the given metric.
+func (c COVRecord) Value(metric Metric) int {
+	switch metric {
+	case MetricNewCases:
+		return c.NewCases
+	case MetricNewDeaths:
+		return c.NewDeaths
+	case MetricTotalCases:
+		return c.TotalCases
+	case MetricTotalDeaths:
+		return c.TotalDeaths
+	case MetricNewTests:
+		return c.NewTests
+	case MetricTotalTests:
+		return c.TotalTests
+	case MetricHospPatients:
+		return c.HospPatients
+	case MetricICUPatients:
+		return c.ICUPatients
+	case MetricNewVaccinations:
+		return c.NewVaccinations
+	case MetricTotalVaccinations:
+		return c.TotalVaccinations
+	case MetricPeopleVaccinated:
+		return c.PeopleVaccinated
+	case MetricPeopleFullyVaccinated:
+		return c.PeopleFullyVaccinated
+	case MetricPopulation:
+		return c.Population
+	}
+	return 0
+}
+
+// Lookup returns the value of the given metric and whether or not it was
+// reported.
+func (c COVRecord) Lookup(metric Metric) (int, bool) {
+	return c.Value(metric), !c.Missing.Has(metric)
+}
+
+// IsMissing returns whether or not the given metric was not reported.
+func (c COVRecord) IsMissing(metric Metric) bool {
+	return c.Missing.Has(metric)
+}
+
+// FormatValue returns the value of the given metric as a string, or "n/a"
+// if it is missing. Nil records have no values.
+func (c *COVRecord) FormatValue(metric Metric) string {
+	if c == nil || c.Missing.Has(metric) {
+		return "n/a"
 	}
+	return strconv.Itoa(c.Value(metric))
+}
 
-	newCases, err := strconv.Atoi(record[2])
-	if err != nil && len(record[2]) > 0 {
-		return nil, err
+// PerMillion returns the value of the given metric per million people of the
+// given population, and whether or not the value was reported and the
+// population is known.
+func (c *COVRecord) PerMillion(metric Metric, population int) (float64, bool) {
+	if c == nil || population <= 0 || c.Missing.Has(metric) {
+		return 0, false
 	}
+	return PerMillion(c.Value(metric), population), true
+}
 
-	newDeaths, err := strconv.Atoi(record[3])
-	if err != nil && len(record[3]) > 0 {
-		return nil, err
+// FormatPerMillion returns the value of the given metric per million people
+// of the given population as a string, or "n/a" if it is missing or the
+// population isn't known.
+func (c *COVRecord) FormatPerMillion(metric Metric, population int) string {
+	value, ok := c.PerMillion(metric, population)
+	if !ok {
+		return "n/a"
 	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// SetMissing marks the given metric as missing and zeroes its value.
+func (c *COVRecord) SetMissing(metric Metric) {
+	c.SetValue(metric, 0)
+	c.Missing = c.Missing.With(metric)
+}
+
+// SetValue sets the value of the given metric and marks it as reported.
+func (c *COVRecord) SetValue(metric Metric, value int) {
+	c.Missing = c.Missing.Without(metric)
+
+	switch metric {
+	case MetricNewCases:
+		c.NewCases = value
+	case MetricNewDeaths:
+		c.NewDeaths = value
+	case MetricTotalCases:
+		c.TotalCases = value
+	case MetricTotalDeaths:
+		c.TotalDeaths = value
+	case MetricNewTests:
+		c.NewTests = value
+	case MetricTotalTests:
+		c.TotalTests = value
+	case MetricHospPatients:
+		c.HospPatients = value
+	case MetricICUPatients:
+		c.ICUPatients = value
+	case MetricNewVaccinations:
+		c.NewVaccinations = value
+	case MetricTotalVaccinations:
+		c.TotalVaccinations = value
+	case MetricPeopleVaccinated:
+		c.PeopleVaccinated = value
+	case MetricPeopleFullyVaccinated:
+		c.PeopleFullyVaccinated = value
+	case MetricPopulation:
+		c.Population = value
+	}
+}
+
+// MARK: Unexported functions
 
-	totalCases, err := strconv.Atoi(record[4])
-	if err != nil && len(record[4]) > 0 {
-		return nil, err
+// parseCount parses an integer count. Some datasets publish counts as
+// floating point numbers, which are rounded.
+func parseCount(field string) (int, error) {
+	value, err := strconv.Atoi(field)
+	if err == nil {
+		return value, nil
 	}
 
-	totalDeaths, err := strconv.Atoi(record[5])
-	if err != nil && len(record[5]) > 0 {
-		return nil, err
+	floatValue, floatErr := strconv.ParseFloat(field, 64)
+	if floatErr != nil {
+		return 0, err
 	}
 
-	return &COVRecord{
-		Date:        date,
-		Location:    record[1],
-		NewCases:    newCases,
-		NewDeaths:   newDeaths,
-		TotalCases:  totalCases,
-		TotalDeaths: totalDeaths,
-	}, nil
+	return int(math.Round(floatValue)), nil
 }
 
 // MARK: String interface methods
 
 func (c COVRecord) String() string {
-	return fmt.Sprintf("%-32v %-32s %-12d %-12d %-12d %-12d", c.Date, c.Location, c.NewCases, c.NewDeaths, c.TotalCases, c.TotalDeaths)
+	return fmt.Sprintf("%-32v %-32s %-12s %-12s %-12s %-12s", c.Date, c.Location, c.FormatValue(MetricNewCases), c.FormatValue(MetricNewDeaths), c.FormatValue(MetricTotalCases), c.FormatValue(MetricTotalDeaths))
 }
@@ -0,0 +1,49 @@
+package models
+
+import "fmt"
+
+// Diagnostic types describe a problem with a row of a CSV dataset.
+type Diagnostic struct {
+
+	// The path of the dataset file.
+	File string `json:"file"`
+
+	// The line of the row in the file.
+	Line int `json:"line"`
+
+	// The column of the field with the problem, if any.
+	Column string `json:"column,omitempty"`
+
+	// The raw value of the field with the problem, if any.
+	Value string `json:"value,omitempty"`
+
+	// A description of the problem.
+	Message string `json:"message"`
+}
+
+// FieldError types describe a field of a row that couldn't be parsed.
+type FieldError struct {
+
+	// The column of the field.
+	Column string
+
+	// The raw value of the field.
+	Value string
+
+	// The underlying error.
+	Err error
+}
+
+// MARK: Error interface methods
+
+func (d Diagnostic) Error() string {
+	position := fmt.Sprintf("%s:%d", d.File, d.Line)
+	if len(d.Column) > 0 {
+		position += fmt.Sprintf(": column %q, value %q", d.Column, d.Value)
+	}
+	return position + ": " + d.Message
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("column %q, value %q: %v", e.Column, e.Value, e.Err)
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// WorldDiff types contain the differences between two versions of a world.
+type WorldDiff struct {
+
+	// The paths of the locations and regions that only the new world
+	// contains.
+	AddedLocations []string `json:"addedLocations"`
+
+	// The paths of the locations and regions that only the old world
+	// contains.
+	RemovedLocations []string `json:"removedLocations"`
+
+	// The paths of the locations and regions in both worlds that had records
+	// added, removed or revised.
+	UpdatedLocations []string `json:"updatedLocations"`
+
+	// The dates that only the new world contains records for.
+	NewDates []string `json:"newDates"`
+
+	// The dates that only the old world contains records for.
+	RemovedDates []string `json:"removedDates"`
+
+	// The number of location and region records that only the new world
+	// contains.
+	AddedRecords int `json:"addedRecords"`
+
+	// The number of location and region records that only the old world
+	// contains.
+	RemovedRecords int `json:"removedRecords"`
+
+	// The values of records in both worlds that were revised.
+	Changes []ValueChange `json:"changes"`
+}
+
+// ValueChange types describe a revised value of a record.
+type ValueChange struct {
+
+	// The path of the record's location, e.g. "United States/New York".
+	Location string `json:"location"`
+
+	// The date of the record.
+	Date time.Time `json:"date"`
+
+	// The metric that changed.
+	Metric Metric `json:"metric"`
+
+	// The old value, or nil if it was missing.
+	Old *int `json:"old"`
+
+	// The new value, or nil if it is missing.
+	New *int `json:"new"`
+}
+
+// MARK: Initializers
+
+// CompareWorlds compares the location records of two versions of a world.
+// Regions, such as states and counties, are compared by their path so that
+// revisions of a region are reported along with its rolled-up location.
+func CompareWorlds(oldWorld *World, newWorld *World) *WorldDiff {
+	diff := &WorldDiff{
+		AddedLocations:   []string{},
+		RemovedLocations: []string{},
+		UpdatedLocations: []string{},
+		NewDates:         []string{},
+		RemovedDates:     []string{},
+		Changes:          []ValueChange{},
+	}
+
+	oldLocations := locationsByPath(oldWorld)
+	newLocations := locationsByPath(newWorld)
+
+	oldDates := make(map[time.Time]bool)
+	newDates := make(map[time.Time]bool)
+
+	for name, oldLocation := range oldLocations {
+		for _, r := range oldLocation.Records {
+			oldDates[r.Date] = true
+		}
+
+		if _, ok := newLocations[name]; !ok {
+			diff.RemovedLocations = append(diff.RemovedLocations, name)
+			diff.RemovedRecords += len(oldLocation.Records)
+		}
+	}
+
+	for name, newLocation := range newLocations {
+		for _, r := range newLocation.Records {
+			newDates[r.Date] = true
+		}
+
+		oldLocation, ok := oldLocations[name]
+		if !ok {
+			diff.AddedLocations = append(diff.AddedLocations, name)
+			diff.AddedRecords += len(newLocation.Records)
+			continue
+		}
+
+		changes := len(diff.Changes)
+		addedRecords := diff.AddedRecords
+
+		// Records that both worlds share are unchanged, which is common when
+		// the new world reuses the records of the old one
+		common := 0
+		for common < len(oldLocation.Records) && common < len(newLocation.Records) &&
+			oldLocation.Records[common] == newLocation.Records[common] {
+			common++
+		}
+
+		oldRecords := recordsByDate(oldLocation.Records[common:])
+		for _, newRecord := range newLocation.Records[common:] {
+			oldRecord, ok := oldRecords[newRecord.Date]
+			if !ok {
+				diff.AddedRecords++
+				continue
+			}
+			delete(oldRecords, newRecord.Date)
+
+			if oldRecord == newRecord {
+				continue
+			}
+
+			for i := range metrics {
+				metric := Metric(i)
+				oldValue, oldOK := oldRecord.Lookup(metric)
+				newValue, newOK := newRecord.Lookup(metric)
+				if oldOK == newOK && oldValue == newValue {
+					continue
+				}
+
+				change := ValueChange{
+					Location: name,
+					Date:     newRecord.Date,
+					Metric:   metric,
+				}
+				if oldOK {
+					change.Old = &oldValue
+				}
+				if newOK {
+					change.New = &newValue
+				}
+				diff.Changes = append(diff.Changes, change)
+			}
+		}
+
+		diff.RemovedRecords += len(oldRecords)
+
+		if len(diff.Changes) > changes || diff.AddedRecords > addedRecords || len(oldRecords) > 0 {
+			diff.UpdatedLocations = append(diff.UpdatedLocations, name)
+		}
+	}
+
+	for date := range newDates {
+		if !oldDates[date] {
+			diff.NewDates = append(diff.NewDates, date.Format("2006-01-02"))
+		}
+	}
+
+	for date := range oldDates {
+		if !newDates[date] {
+			diff.RemovedDates = append(diff.RemovedDates, date.Format("2006-01-02"))
+		}
+	}
+
+	sort.Strings(diff.AddedLocations)
+	sort.Strings(diff.RemovedLocations)
+	sort.Strings(diff.UpdatedLocations)
+	sort.Strings(diff.NewDates)
+	sort.Strings(diff.RemovedDates)
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.Metric < b.Metric
+	})
+
+	return diff
+}
+
+// MARK: Exported methods
+
+// Empty returns whether or not the worlds had no differences.
+func (d WorldDiff) Empty() bool {
+	return len(d.AddedLocations) == 0 &&
+		len(d.RemovedLocations) == 0 &&
+		d.AddedRecords == 0 &&
+		d.RemovedRecords == 0 &&
+		len(d.Changes) == 0
+}
+
+// MARK: Unexported functions
+
+// locationsByPath returns the world's locations and their regions keyed by
+// path.
+func locationsByPath(world *World) map[string]*Location {
+	locations := make(map[string]*Location)
+	for _, l := range world.Locations {
+		l.Walk(func(region *Location) {
+			locations[region.Path()] = region
+		})
+	}
+	return locations
+}
+
+// recordsByDate returns the records keyed by date.
+func recordsByDate(records []*COVRecord) map[time.Time]*COVRecord {
+	byDate := make(map[time.Time]*COVRecord)
+	for _, r := range records {
+		byDate[r.Date] = r
+	}
+	return byDate
+}
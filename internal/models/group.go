@@ -0,0 +1,110 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// The members of the built-in groups that aren't continents.
+var defaultGroupMembers = map[string][]string{
+	"European Union": {
+		"Austria", "Belgium", "Bulgaria", "Croatia", "Cyprus", "Czechia",
+		"Denmark", "Estonia", "Finland", "France", "Germany", "Greece",
+		"Hungary", "Ireland", "Italy", "Latvia", "Lithuania", "Luxembourg",
+		"Malta", "Netherlands", "Poland", "Portugal", "Romania", "Slovakia",
+		"Slovenia", "Spain", "Sweden",
+	},
+	"G7": {
+		"Canada", "France", "Germany", "Italy", "Japan", "United Kingdom",
+		"United States",
+	},
+}
+
+// LocationGroup types are named sets of locations whose records are summed
+// in to an aggregate location.
+type LocationGroup struct {
+
+	// The group's name.
+	Name string
+
+	// The names or ISO codes of the group's locations.
+	Members []string
+}
+
+// LocationGroups types contain location groups. Groups are looked up
+// case-insensitively by name.
+type LocationGroups struct {
+	groups []*LocationGroup
+	index  map[string]*LocationGroup
+}
+
+// MARK: Initializers
+
+// NewLocationGroups creates and returns new location groups. Groups replace
+// earlier groups with the same name.
+func NewLocationGroups(groups []*LocationGroup) *LocationGroups {
+	g := &LocationGroups{index: make(map[string]*LocationGroup)}
+	for _, group := range groups {
+		g.Add(group)
+	}
+	return g
+}
+
+// DefaultLocationGroups creates and returns the built-in location groups: the
+// European Union, the G7 and a group for each continent of the locations in
+// the table.
+func DefaultLocationGroups(table *LocationTable) *LocationGroups {
+	continents := make(map[string][]string)
+	for _, info := range table.Infos() {
+		if len(info.Continent) > 0 {
+			continents[info.Continent] = append(continents[info.Continent], info.Name)
+		}
+	}
+
+	var groups []*LocationGroup
+	for name, members := range continents {
+		groups = append(groups, &LocationGroup{Name: name, Members: members})
+	}
+
+	for name, members := range defaultGroupMembers {
+		groups = append(groups, &LocationGroup{Name: name, Members: members})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return NewLocationGroups(groups)
+}
+
+// MARK: Exported methods
+
+// Groups returns the groups.
+func (g LocationGroups) Groups() []*LocationGroup {
+	return g.groups
+}
+
+// Lookup returns the group with the given name.
+func (g LocationGroups) Lookup(name string) (*LocationGroup, bool) {
+	group, ok := g.index[strings.ToLower(strings.TrimSpace(name))]
+	return group, ok
+}
+
+// Add adds the group, replacing the group with the same name.
+func (g *LocationGroups) Add(group *LocationGroup) {
+	key := strings.ToLower(group.Name)
+	if existing, ok := g.index[key]; ok {
+		for i, other := range g.groups {
+			if other == existing {
+				g.groups[i] = group
+			}
+		}
+	} else {
+		g.groups = append(g.groups, group)
+	}
+	g.index[key] = group
+}
+
+// Merge adds the groups of other, replacing the groups with the same names.
+func (g *LocationGroups) Merge(other *LocationGroups) {
+	for _, group := range other.groups {
+		g.Add(group)
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWorldAggregate(t *testing.T) {
+	date := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	location := func(name string, cases int) *Location {
+		return NewLocation(name, []*COVRecord{{Date: date, Location: name, NewCases: cases, TotalCases: cases}})
+	}
+
+	// JHU names the United States and South Korea differently to the
+	// location metadata and the built-in groups
+	table := DefaultLocationTable()
+	world := NewWorldFromLocations([]*Location{
+		location("Canada", 1),
+		location("Italy", 2),
+		location("Japan", 4),
+		location("Korea, South", 8),
+		location("US", 16),
+	})
+	world.JoinLocationInfo(table)
+
+	// ECDC's names are only aliases of the metadata's names
+	unjoined := NewWorldFromLocations([]*Location{
+		location("United States of America", 64),
+		location("Japan", 128),
+	})
+
+	groups := DefaultLocationGroups(table)
+
+	tests := []struct {
+		world      *World
+		group      string
+		cases      int
+		population int
+	}{
+		{world, "G7", 23, 37742157 + 60461828 + 126476458 + 331002647},
+		{world, "Asia", 12, 126476458 + 51269183},
+		{world, "North America", 17, 37742157 + 331002647},
+		{world, "Oceania", 0, 0},
+		{unjoined, "G7", 192, 0},
+		{unjoined, "Asia", 128, 0},
+	}
+
+	for _, test := range tests {
+		group, ok := groups.Lookup(test.group)
+		if !ok {
+			t.Fatalf("the %s group isn't built in", test.group)
+		}
+
+		aggregate := test.world.Aggregate(group)
+		if test.cases == 0 {
+			if aggregate != nil {
+				t.Errorf("%s aggregate = %v, want nil", test.group, aggregate.Records)
+			}
+			continue
+		}
+
+		if aggregate == nil {
+			t.Errorf("%s aggregate = nil, want %d cases", test.group, test.cases)
+			continue
+		}
+
+		if aggregate.Name != test.group || aggregate.Value(MetricTotalCases) != test.cases {
+			t.Errorf("%s aggregate = %d cases, want %d", aggregate.Name, aggregate.Value(MetricTotalCases), test.cases)
+		}
+		if aggregate.Info.Population != test.population {
+			t.Errorf("%s population = %d, want %d", test.group, aggregate.Info.Population, test.population)
+		}
+	}
+
+	// Matching a location's aliases doesn't change them
+	if aliases := LocationAliases("US"); !reflect.DeepEqual(aliases, LocationAliases("United States")) {
+		t.Errorf("aliases of US = %v", aliases)
+	}
+}
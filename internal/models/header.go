@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The default alternative names of columns keyed by canonical column name.
+// Names like "cases" and "deaths" are daily counts in some datasets and
+// totals in others, so they are left to configured aliases.
+var defaultColumnAliases = map[string][]string{
+	"date":         {"date", "report_date"},
+	"location":     {"location", "country", "country/region", "country_region"},
+	"new_cases":    {"new_cases", "newcases"},
+	"new_deaths":   {"new_deaths", "newdeaths"},
+	"total_cases":  {"total_cases", "totalcases", "confirmed", "cumulative_cases"},
+	"total_deaths": {"total_deaths", "totaldeaths", "cumulative_deaths"},
+}
+
+// ParseOptions types contain options for parsing CSV datasets.
+type ParseOptions struct {
+
+	// Additional alternative names of columns keyed by canonical column
+	// name, e.g. "location": ["Country"].
+	ColumnAliases map[string][]string
+
+	// Whether or not rows that can't be parsed are skipped and reported as
+	// diagnostics of the world instead of failing the parse.
+	Lenient bool
+
+	// The number of goroutines that parse large datasets in parallel. Zero
+	// uses one per CPU and one parses sequentially. The parsed world is the
+	// same for any number of workers, so it isn't part of the options'
+	// encoding.
+	Workers int `json:"-"`
+}
+
+// Header types map the canonical names of the columns of a CSV dataset to
+// their index.
+type Header struct {
+	columns map[string]int
+	count   int
+}
+
+// MARK: Initializers
+
+// NewHeader creates and returns a new header from the header row of a CSV
+// dataset. Columns are matched case-insensitively by their canonical name or
+// one of their aliases, and columns that match nothing are ignored.
+func NewHeader(record []string, options *ParseOptions) *Header {
+	names := make(map[string]string)
+	addAliases := func(aliases map[string][]string) {
+		for column, alternatives := range aliases {
+			column = strings.ToLower(column)
+			names[column] = column
+			for _, alternative := range alternatives {
+				names[strings.ToLower(alternative)] = column
+			}
+		}
+	}
+
+	addAliases(defaultColumnAliases)
+	if options != nil {
+		addAliases(options.ColumnAliases)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		column, ok := names[name]
+		if !ok {
+			column = name
+		}
+
+		if _, ok := columns[column]; !ok {
+			columns[column] = i
+		}
+	}
+
+	return &Header{columns: columns, count: len(record)}
+}
+
+// MARK: Exported functions
+
+// RequiredColumns returns the canonical names of the columns that every CSV
+// dataset must contain.
+func RequiredColumns() []string {
+	columns := []string{"date", "location"}
+	for _, metric := range DefaultMetrics {
+		columns = append(columns, metric.Column())
+	}
+	return columns
+}
+
+// MARK: Exported methods
+
+// Len returns the number of columns in the header row.
+func (h Header) Len() int {
+	return h.count
+}
+
+// Has returns whether or not the header contains the column with the given
+// canonical name.
+func (h Header) Has(column string) bool {
+	_, ok := h.columns[column]
+	return ok
+}
+
+// Require returns an error listing every given column that the header does
+// not contain.
+func (h Header) Require(columns ...string) error {
+	var missing []string
+	for _, column := range columns {
+		if !h.Has(column) {
+			missing = append(missing, fmt.Sprintf("%q", column))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required column(s) %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// MARK: Unexported methods
+
+// value returns the field of the record in the column with the given name or
+// an empty string if the header doesn't contain the column.
+func (h Header) value(record []string, column string) string {
+	if i, ok := h.columns[column]; ok && i < len(record) {
+		return record[i]
+	}
+	return ""
+}
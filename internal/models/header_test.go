@@ -0,0 +1,86 @@
+package models
+
+import "testing"
+
+func TestNewHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  []string
+		options *ParseOptions
+		columns map[string]int
+		absent  []string
+	}{
+		{
+			name:    "canonical",
+			record:  []string{"date", "location", "new_cases", "new_deaths", "total_cases", "total_deaths"},
+			columns: map[string]int{"date": 0, "location": 1, "new_cases": 2, "new_deaths": 3, "total_cases": 4, "total_deaths": 5},
+		},
+		{
+			name:    "default aliases",
+			record:  []string{"\ufeffReport_Date", " Country/Region ", "NewCases", "NewDeaths", "Confirmed", "TotalDeaths"},
+			columns: map[string]int{"date": 0, "location": 1, "new_cases": 2, "new_deaths": 3, "total_cases": 4, "total_deaths": 5},
+		},
+		{
+			name:    "ambiguous names",
+			record:  []string{"date", "state", "cases", "deaths"},
+			columns: map[string]int{"date": 0},
+			absent:  []string{"location", "new_cases", "new_deaths", "total_cases", "total_deaths"},
+		},
+		{
+			name:   "configured aliases",
+			record: []string{"date", "state", "cases", "deaths"},
+			options: &ParseOptions{ColumnAliases: map[string][]string{
+				"location":     {"State"},
+				"total_cases":  {"cases"},
+				"total_deaths": {"deaths"},
+			}},
+			columns: map[string]int{"date": 0, "location": 1, "total_cases": 2, "total_deaths": 3},
+			absent:  []string{"new_cases", "new_deaths"},
+		},
+		{
+			name:    "first column wins",
+			record:  []string{"location", "country", "date"},
+			columns: map[string]int{"location": 0, "date": 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := NewHeader(test.record, test.options)
+			if header.Len() != len(test.record) {
+				t.Errorf("Len() = %d, want %d", header.Len(), len(test.record))
+			}
+
+			for column, i := range test.columns {
+				if !header.Has(column) {
+					t.Errorf("Has(%q) = false", column)
+				} else if got := header.value(test.record, column); got != test.record[i] {
+					t.Errorf("value of %q = %q, want %q", column, got, test.record[i])
+				}
+			}
+
+			for _, column := range test.absent {
+				if header.Has(column) {
+					t.Errorf("Has(%q) = true", column)
+				}
+			}
+		})
+	}
+}
+
+func TestHeaderRequire(t *testing.T) {
+	header := NewHeader([]string{"date", "location", "new_cases", "total_cases"}, nil)
+
+	if err := header.Require("date", "location"); err != nil {
+		t.Errorf("Require of present columns = %v", err)
+	}
+
+	err := header.Require(RequiredColumns()...)
+	if err == nil || err.Error() != `missing required column(s) "new_deaths", "total_deaths"` {
+		t.Errorf("Require(RequiredColumns()...) = %v", err)
+	}
+
+	if got := header.value([]string{"2020-03-01"}, "location"); got != "" {
+		t.Errorf("value of a short row = %q, want empty", got)
+	}
+}
@@ -0,0 +1,97 @@
+package models
+
+import "strings"
+
+// LocationPathSeparator separates the names of a location path, e.g.
+// "United States/New York/Kings".
+const LocationPathSeparator = "/"
+
+// MARK: Exported methods
+
+// AddChild adds the child to the location's regions and sets its parent to
+// the location.
+func (l *Location) AddChild(child *Location) {
+	if l.childIndex == nil {
+		l.childIndex = make(map[string]*Location)
+	}
+
+	child.Parent = l
+	l.Children = append(l.Children, child)
+
+	key := normalizeLocationName(child.Name)
+	if _, ok := l.childIndex[key]; !ok {
+		l.childIndex[key] = child
+	}
+}
+
+// Child returns the region of the location with the given case-insensitive
+// name or alias, or nil if the location doesn't have one.
+func (l Location) Child(name string) *Location {
+	index := l.childIndex
+	if index == nil {
+		index = newLocationIndex(l.Children)
+	}
+
+	if child, ok := index[normalizeLocationName(name)]; ok {
+		return child
+	}
+
+	for _, alias := range LocationAliases(name) {
+		if child, ok := index[normalizeLocationName(alias)]; ok {
+			return child
+		}
+	}
+	return nil
+}
+
+// Path returns the names of the location and its parents from the top-level
+// location down, joined by the path separator.
+func (l Location) Path() string {
+	names := []string{l.Name}
+	for parent := l.Parent; parent != nil; parent = parent.Parent {
+		names = append([]string{parent.Name}, names...)
+	}
+	return strings.Join(names, LocationPathSeparator)
+}
+
+// Depth returns the number of parents of the location.
+func (l Location) Depth() int {
+	depth := 0
+	for parent := l.Parent; parent != nil; parent = parent.Parent {
+		depth++
+	}
+	return depth
+}
+
+// RollUp sets the records of the location and each of its regions that have
+// regions, but no records of their own, to the sum of their regions' records.
+// Regions are rolled up before the locations that contain them.
+func (l *Location) RollUp() {
+	for _, child := range l.Children {
+		child.RollUp()
+	}
+
+	if len(l.Children) > 0 && len(l.Records) == 0 {
+		l.Records = AggregateRecords(l.Name, l.Children)
+	}
+}
+
+// Walk calls fn with the location and each of its regions, parents before
+// their regions.
+func (l *Location) Walk(fn func(l *Location)) {
+	fn(l)
+	for _, child := range l.Children {
+		child.Walk(fn)
+	}
+}
+
+// MARK: Exported functions
+
+// SplitLocationPath returns the names of a location path.
+func SplitLocationPath(path string) []string {
+	names := strings.Split(path, LocationPathSeparator)
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names
+}
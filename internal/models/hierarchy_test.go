@@ -0,0 +1,113 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWorldLocationPaths(t *testing.T) {
+	world := testRegionWorld(map[string]int{"Kings": 10, "Queens": 5, "Travis": 3})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"United States", "United States"},
+		{"United States/New York", "United States/New York"},
+		{"united states/new york/kings", "United States/New York/Kings"},
+		{"USA / New York / Queens", "United States/New York/Queens"},
+		{"US/Texas/Travis", "United States/Texas/Travis"},
+		{"United States/New York/Travis", ""},
+		{"United States/Nowhere", ""},
+		{"Nowhere/New York", ""},
+		{"New York", ""},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if l := world.Location(test.path); l != nil {
+			got = l.Path()
+		}
+
+		if got != test.want {
+			t.Errorf("Location(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+
+	if newYork := world.Location("United States/New York"); newYork.Depth() != 1 || newYork.Records[0].TotalCases != 15 {
+		t.Errorf("New York isn't the roll-up of its counties")
+	}
+}
+
+func TestCompareWorldsRegions(t *testing.T) {
+	oldWorld := testRegionWorld(map[string]int{"Kings": 10, "Queens": 5, "Travis": 3})
+	newWorld := testRegionWorld(map[string]int{"Kings": 12, "Queens": 5, "Bronx": 4})
+
+	diff := CompareWorlds(oldWorld, newWorld)
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"added", diff.AddedLocations, []string{"United States/New York/Bronx"}},
+		{"removed", diff.RemovedLocations, []string{"United States/Texas", "United States/Texas/Travis"}},
+		{"updated", diff.UpdatedLocations, []string{"United States", "United States/New York", "United States/New York/Kings"}},
+	}
+
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s locations = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+
+	changed := make(map[string]bool)
+	for _, change := range diff.Changes {
+		if change.Metric == MetricTotalCases {
+			changed[change.Location] = true
+		}
+	}
+
+	for _, path := range []string{"United States", "United States/New York", "United States/New York/Kings"} {
+		if !changed[path] {
+			t.Errorf("total cases of %s didn't change, changes = %v", path, diff.Changes)
+		}
+	}
+	if changed["United States/New York/Queens"] {
+		t.Errorf("total cases of Queens changed")
+	}
+}
+
+// testRegionWorld returns a world of the United States with one record of
+// the total cases of each of the given counties. Bronx, Kings and Queens are
+// counties of New York and the others are counties of Texas.
+func testRegionWorld(counties map[string]int) *World {
+	date := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	country := NewLocation("United States", nil)
+	states := make(map[string]*Location)
+
+	for _, county := range []string{"Bronx", "Kings", "Queens", "Travis"} {
+		cases, ok := counties[county]
+		if !ok {
+			continue
+		}
+
+		state := "Texas"
+		if county != "Travis" {
+			state = "New York"
+		}
+
+		if states[state] == nil {
+			states[state] = NewLocation(state, nil)
+			country.AddChild(states[state])
+		}
+
+		states[state].AddChild(NewLocation(county, []*COVRecord{
+			&COVRecord{Date: date, Location: county, NewCases: cases, TotalCases: cases},
+		}))
+	}
+
+	country.RollUp()
+	return NewWorldFromLocations([]*Location{country})
+}
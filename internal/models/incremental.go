@@ -0,0 +1,117 @@
+package models
+
+import "hash/crc64"
+
+// Fingerprint types identify the raw row a record was parsed from by two
+// independent hashes of its fields. Rows are unchanged if both of their
+// hashes are equal.
+type Fingerprint struct {
+
+	// The 64-bit FNV-1a hash of the row.
+	FNV uint64
+
+	// The 64-bit ECMA CRC of the row.
+	CRC uint64
+}
+
+// Fingerprints types contain the fingerprints of the rows that the records
+// of a world were parsed from. They are keyed by location name and are in the
+// order of the location's records.
+type Fingerprints map[string][]Fingerprint
+
+// rowIndex types contain the records of a previously parsed world keyed by
+// the fingerprints of their rows.
+type rowIndex map[Fingerprint]*COVRecord
+
+// crcTable is the table of the CRC of row fingerprints.
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+// fieldSeparator follows each field of a row in its CRC.
+var fieldSeparator = []byte{0}
+
+// MARK: Initializers
+
+// UpdateWorldFromPath parses the CSV dataset at path like
+// NewWorldFromPathWithOptions, but reuses the records of base whose rows
+// haven't changed since base was parsed. Only new and revised rows are
+// parsed. The fingerprints identify the rows base was parsed from, and the
+// fingerprints of the new world's rows are returned with it.
+//
+// A nil base parses every row. Base must have been parsed with the same
+// options.
+func UpdateWorldFromPath(base *World, fingerprints Fingerprints, path string, options *ParseOptions) (*World, Fingerprints, error) {
+	covRecords, rowFingerprints, diagnostics, err := readRecords(path, options, newRowIndex(base, fingerprints))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	world := newWorldFromRecords(covRecords, diagnostics)
+
+	newFingerprints := make(Fingerprints)
+	for i, r := range covRecords {
+		newFingerprints[r.Location] = append(newFingerprints[r.Location], rowFingerprints[i])
+	}
+
+	return world, newFingerprints, nil
+}
+
+// newRowIndex creates and returns a new row index of the world's records. A
+// nil world creates an empty index.
+func newRowIndex(world *World, fingerprints Fingerprints) rowIndex {
+	index := make(rowIndex)
+	if world == nil {
+		return index
+	}
+
+	add := func(name string, records []*COVRecord) {
+		locationFingerprints := fingerprints[name]
+		if len(locationFingerprints) != len(records) {
+			return
+		}
+
+		for i, r := range records {
+			index[locationFingerprints[i]] = r
+		}
+	}
+
+	for _, l := range world.Locations {
+		add(l.Name, l.Records)
+	}
+
+	// Synthesized records weren't parsed from any rows
+	if !world.Synthesized && len(world.Records) > 0 {
+		add(world.Records[0].Location, world.Records)
+	}
+
+	return index
+}
+
+// MARK: Unexported methods
+
+// lookup returns the indexed record of the row with the fingerprint if the
+// row is unchanged, or nil otherwise.
+func (i rowIndex) lookup(fingerprint Fingerprint) *COVRecord {
+	return i[fingerprint]
+}
+
+// MARK: Unexported functions
+
+// fingerprintRow returns the fingerprint of the raw row's fields, each
+// followed by a zero byte.
+func fingerprintRow(record []string) Fingerprint {
+	const offset = 14695981039346656037
+	const prime = 1099511628211
+
+	fingerprint := Fingerprint{FNV: offset}
+	for _, field := range record {
+		for i := 0; i < len(field); i++ {
+			fingerprint.FNV ^= uint64(field[i])
+			fingerprint.FNV *= prime
+		}
+		fingerprint.FNV *= prime
+
+		fingerprint.CRC = crc64.Update(fingerprint.CRC, crcTable, []byte(field))
+		fingerprint.CRC = crc64.Update(fingerprint.CRC, crcTable, fieldSeparator)
+	}
+	return fingerprint
+}
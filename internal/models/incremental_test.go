@@ -0,0 +1,82 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRowIndexLookup(t *testing.T) {
+	header := NewHeader(strings.Split("date,location,new_cases,new_deaths,total_cases,total_deaths", ","), nil)
+	row := strings.Split("2020-03-01,Italy,5,1,10,2", ",")
+
+	indexed, err := NewCOVRecord(header, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := fingerprintRow(row)
+	index := rowIndex{fingerprint: indexed}
+
+	tests := []struct {
+		name  string
+		row   string
+		reuse bool
+	}{
+		{"unchanged", "2020-03-01,Italy,5,1,10,2", true},
+		{"formatted as a float", "2020-03-01,Italy,5.0,1,10,2", false},
+		{"revised value", "2020-03-01,Italy,5,1,11,2", false},
+		{"missing value", "2020-03-01,Italy,5,1,,2", false},
+		{"different date", "2020-03-02,Italy,5,1,10,2", false},
+		{"different location", "2020-03-01,Spain,5,1,10,2", false},
+		{"moved separator", "2020-03-01,Italy,5,1,1,02", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := index.lookup(fingerprintRow(strings.Split(test.row, ","))); (got == indexed) != test.reuse {
+				t.Errorf("expected reuse %v, got %v", test.reuse, got)
+			}
+		})
+	}
+
+	// Rows whose hashes collide are only unchanged if both hashes are equal
+	for _, collision := range []Fingerprint{{FNV: fingerprint.FNV}, {CRC: fingerprint.CRC}} {
+		if got := index.lookup(collision); got != nil {
+			t.Errorf("lookup(%v) = %v, want nil", collision, got)
+		}
+	}
+}
+
+func TestUpdateWorldFromPath(t *testing.T) {
+	original := `date,location,new_cases,new_deaths,total_cases,total_deaths
+2020-03-01,Italy,5,1,10,2
+2020-03-02,Italy,5,1,15,3
+`
+	base, fingerprints, err := UpdateWorldFromPath(nil, nil, writeDataset(t, original), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revised := `date,location,new_cases,new_deaths,total_cases,total_deaths
+2020-03-01,Italy,5,1,10,2
+2020-03-02,Italy,6,1,16,3
+2020-03-03,Italy,4,0,20,3
+`
+	world, _, err := UpdateWorldFromPath(base, fingerprints, writeDataset(t, revised), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := world.Location("Italy").Records
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	if records[0] != base.Location("Italy").Records[0] {
+		t.Error("expected the unchanged row's record to be reused")
+	}
+
+	if records[1].TotalCases != 16 || records[2].TotalCases != 20 {
+		t.Errorf("expected the revised and new rows to be parsed, got %v and %v", records[1], records[2])
+	}
+}
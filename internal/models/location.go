@@ -10,6 +10,20 @@ type Location struct {
 
 	// The location's records.
 	Records []*COVRecord
+
+	// The location's metadata, or nil if it isn't known.
+	Info *LocationInfo
+
+	// The location that contains the location, or nil if it is a top-level
+	// location.
+	Parent *Location
+
+	// The regions of the location, such as the states of a country or the
+	// counties of a state. Use AddChild to add them.
+	Children []*Location
+
+	// The children keyed by their normalized names.
+	childIndex map[string]*Location
 }
 
 // MARK: Initializers
@@ -56,28 +70,80 @@ func (l Location) TotalDeaths() int {
 	return 0
 }
 
-// TotalCasesSignal returns the location's records' total cases
-// as a float slice.
-func (l Location) TotalCasesSignal() []float64 {
-	var signal []float64
-	for _, r := range l.Records {
-		signal = append(signal, float64(r.TotalCases))
+// Value returns the latest value of the given metric at the location.
+func (l Location) Value(metric Metric) int {
+	if len(l.Records) > 0 {
+		return l.Records[len(l.Records)-1].Value(metric)
 	}
-	return signal
+	return 0
 }
 
-// TotalDeathsSignal returns the location's records' total deaths
-// as a float slice.
-func (l Location) TotalDeathsSignal() []float64 {
-	var signal []float64
-	for _, r := range l.Records {
-		signal = append(signal, float64(r.TotalDeaths))
+// Lookup returns the latest value of the given metric at the location and
+// whether or not it was reported.
+func (l Location) Lookup(metric Metric) (int, bool) {
+	if len(l.Records) > 0 {
+		return l.Records[len(l.Records)-1].Lookup(metric)
 	}
-	return signal
+	return 0, false
+}
+
+// Signal returns the location's records' values of the given metric as a
+// float slice with missing values treated according to policy.
+func (l Location) Signal(metric Metric, policy MissingPolicy) []float64 {
+	return recordsSignal(l.Records, metric, policy)
+}
+
+// Series returns the location's series of the given metric. Dates that the
+// location has records of but didn't report the metric on are gaps.
+func (l Location) Series(metric Metric) *Series {
+	return NewSeries(l.Name, l.Records, metric)
+}
+
+// Population returns the location's population from its metadata, or the
+// latest population it reported if its metadata doesn't have one. Zero is
+// returned if the population isn't known.
+func (l Location) Population() int {
+	if l.Info != nil && l.Info.Population > 0 {
+		return l.Info.Population
+	}
+	return latestPopulation(l.Records)
+}
+
+// PerMillion returns the latest value of the given metric at the location
+// per million people, and whether or not the value was reported and the
+// location's population is known.
+func (l Location) PerMillion(metric Metric) (float64, bool) {
+	if len(l.Records) == 0 {
+		return 0, false
+	}
+	return l.Records[len(l.Records)-1].PerMillion(metric, l.Population())
+}
+
+// PerMillionSignal returns the location's records' values of the given metric
+// per million people as a float slice with missing values treated according
+// to policy, or nil if the location's population isn't known.
+func (l Location) PerMillionSignal(metric Metric, policy MissingPolicy) []float64 {
+	return perMillionSignal(l.Signal(metric, policy), l.Population())
+}
+
+// TotalCasesSignal returns the location's records' total cases
+// as a float slice with missing values treated according to policy.
+func (l Location) TotalCasesSignal(policy MissingPolicy) []float64 {
+	return l.Signal(MetricTotalCases, policy)
+}
+
+// TotalDeathsSignal returns the location's records' total deaths
+// as a float slice with missing values treated according to policy.
+func (l Location) TotalDeathsSignal(policy MissingPolicy) []float64 {
+	return l.Signal(MetricTotalDeaths, policy)
 }
 
 // MARK: String interface methods
 
 func (l Location) String() string {
-	return fmt.Sprintf("%-32s %-12d %-12d %-12d %-12d", l.Name, l.NewCases(), l.NewDeaths(), l.TotalCases(), l.TotalDeaths())
+	var last *COVRecord
+	if len(l.Records) > 0 {
+		last = l.Records[len(l.Records)-1]
+	}
+	return fmt.Sprintf("%-32s %-12s %-12s %-12s %-12s", l.Name, last.FormatValue(MetricNewCases), last.FormatValue(MetricNewDeaths), last.FormatValue(MetricTotalCases), last.FormatValue(MetricTotalDeaths))
 }
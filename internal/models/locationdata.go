@@ -0,0 +1,240 @@
+package models
+
+// defaultLocationInfo is the CSV table of the default location metadata.
+// Populations are mid-2020 estimates from the UN World Population Prospects,
+// and aggregate regions use Our World in Data's codes.
+const defaultLocationInfo = `name,iso2,iso3,continent,population
+Afghanistan,AF,AFG,Asia,38928341
+Albania,AL,ALB,Europe,2877800
+Algeria,DZ,DZA,Africa,43851043
+Andorra,AD,AND,Europe,77265
+Angola,AO,AGO,Africa,32866268
+Anguilla,AI,AIA,North America,15002
+Antigua and Barbuda,AG,ATG,North America,97928
+Argentina,AR,ARG,South America,45195777
+Armenia,AM,ARM,Asia,2963234
+Aruba,AW,ABW,North America,106766
+Australia,AU,AUS,Oceania,25499881
+Austria,AT,AUT,Europe,9006400
+Azerbaijan,AZ,AZE,Asia,10139175
+Bahamas,BS,BHS,North America,393248
+Bahrain,BH,BHR,Asia,1701583
+Bangladesh,BD,BGD,Asia,164689383
+Barbados,BB,BRB,North America,287371
+Belarus,BY,BLR,Europe,9449321
+Belgium,BE,BEL,Europe,11589616
+Belize,BZ,BLZ,North America,397621
+Benin,BJ,BEN,Africa,12123198
+Bermuda,BM,BMU,North America,62273
+Bhutan,BT,BTN,Asia,771612
+Bolivia,BO,BOL,South America,11673029
+Bonaire Sint Eustatius and Saba,BQ,BES,North America,26221
+Bosnia and Herzegovina,BA,BIH,Europe,3280815
+Botswana,BW,BWA,Africa,2351625
+Brazil,BR,BRA,South America,212559409
+British Virgin Islands,VG,VGB,North America,30237
+Brunei,BN,BRN,Asia,437483
+Bulgaria,BG,BGR,Europe,6948445
+Burkina Faso,BF,BFA,Africa,20903278
+Burundi,BI,BDI,Africa,11890781
+Cambodia,KH,KHM,Asia,16718971
+Cameroon,CM,CMR,Africa,26545864
+Canada,CA,CAN,North America,37742157
+Cape Verde,CV,CPV,Africa,555988
+Cayman Islands,KY,CYM,North America,65720
+Central African Republic,CF,CAF,Africa,4829764
+Chad,TD,TCD,Africa,16425859
+Chile,CL,CHL,South America,19116209
+China,CN,CHN,Asia,1439323774
+Colombia,CO,COL,South America,50882884
+Comoros,KM,COM,Africa,869595
+Congo,CG,COG,Africa,5518092
+Costa Rica,CR,CRI,North America,5094114
+Cote d'Ivoire,CI,CIV,Africa,26378275
+Croatia,HR,HRV,Europe,4105268
+Cuba,CU,CUB,North America,11326616
+Curacao,CW,CUW,North America,164100
+Cyprus,CY,CYP,Europe,875899
+Czechia,CZ,CZE,Europe,10708982
+Democratic Republic of Congo,CD,COD,Africa,89561404
+Denmark,DK,DNK,Europe,5792203
+Djibouti,DJ,DJI,Africa,988002
+Dominica,DM,DMA,North America,71991
+Dominican Republic,DO,DOM,North America,10847904
+Ecuador,EC,ECU,South America,17643060
+Egypt,EG,EGY,Africa,102334403
+El Salvador,SV,SLV,North America,6486201
+Equatorial Guinea,GQ,GNQ,Africa,1402985
+Eritrea,ER,ERI,Africa,3546427
+Estonia,EE,EST,Europe,1326539
+Eswatini,SZ,SWZ,Africa,1160164
+Ethiopia,ET,ETH,Africa,114963583
+Falkland Islands,FK,FLK,South America,3483
+Faeroe Islands,FO,FRO,Europe,48865
+Fiji,FJ,FJI,Oceania,896444
+Finland,FI,FIN,Europe,5540718
+France,FR,FRA,Europe,65273512
+French Polynesia,PF,PYF,Oceania,280904
+Gabon,GA,GAB,Africa,2225728
+Gambia,GM,GMB,Africa,2416664
+Georgia,GE,GEO,Asia,3989175
+Germany,DE,DEU,Europe,83783945
+Ghana,GH,GHA,Africa,31072945
+Gibraltar,GI,GIB,Europe,33691
+Greece,GR,GRC,Europe,10423056
+Greenland,GL,GRL,North America,56772
+Grenada,GD,GRD,North America,112519
+Guam,GU,GUM,Oceania,168783
+Guatemala,GT,GTM,North America,17915567
+Guernsey,GG,GGY,Europe,67052
+Guinea,GN,GIN,Africa,13132792
+Guinea-Bissau,GW,GNB,Africa,1967998
+Guyana,GY,GUY,South America,786559
+Haiti,HT,HTI,North America,11402533
+Honduras,HN,HND,North America,9904608
+Hong Kong,HK,HKG,Asia,7496988
+Hungary,HU,HUN,Europe,9660350
+Iceland,IS,ISL,Europe,341250
+India,IN,IND,Asia,1380004385
+Indonesia,ID,IDN,Asia,273523621
+Iran,IR,IRN,Asia,83992953
+Iraq,IQ,IRQ,Asia,40222503
+Ireland,IE,IRL,Europe,4937796
+Isle of Man,IM,IMN,Europe,85032
+Israel,IL,ISR,Asia,8655541
+Italy,IT,ITA,Europe,60461828
+Jamaica,JM,JAM,North America,2961161
+Japan,JP,JPN,Asia,126476458
+Jersey,JE,JEY,Europe,101073
+Jordan,JO,JOR,Asia,10203140
+Kazakhstan,KZ,KAZ,Asia,18776707
+Kenya,KE,KEN,Africa,53771300
+Kiribati,KI,KIR,Oceania,119446
+Kosovo,XK,OWID_KOS,Europe,1932774
+Kuwait,KW,KWT,Asia,4270563
+Kyrgyzstan,KG,KGZ,Asia,6524191
+Laos,LA,LAO,Asia,7275556
+Latvia,LV,LVA,Europe,1886202
+Lebanon,LB,LBN,Asia,6825442
+Lesotho,LS,LSO,Africa,2142252
+Liberia,LR,LBR,Africa,5057677
+Libya,LY,LBY,Africa,6871287
+Liechtenstein,LI,LIE,Europe,38137
+Lithuania,LT,LTU,Europe,2722291
+Luxembourg,LU,LUX,Europe,625976
+Macao,MO,MAC,Asia,649342
+Madagascar,MG,MDG,Africa,27691019
+Malawi,MW,MWI,Africa,19129955
+Malaysia,MY,MYS,Asia,32365998
+Maldives,MV,MDV,Asia,540542
+Mali,ML,MLI,Africa,20250834
+Malta,MT,MLT,Europe,441539
+Marshall Islands,MH,MHL,Oceania,59194
+Mauritania,MR,MRT,Africa,4649660
+Mauritius,MU,MUS,Africa,1271767
+Mexico,MX,MEX,North America,128932753
+Micronesia (country),FM,FSM,Oceania,115021
+Moldova,MD,MDA,Europe,4033963
+Monaco,MC,MCO,Europe,39244
+Mongolia,MN,MNG,Asia,3278292
+Montenegro,ME,MNE,Europe,628062
+Montserrat,MS,MSR,North America,4999
+Morocco,MA,MAR,Africa,36910558
+Mozambique,MZ,MOZ,Africa,31255435
+Myanmar,MM,MMR,Asia,54409794
+Namibia,NA,NAM,Africa,2540916
+Nauru,NR,NRU,Oceania,10834
+Nepal,NP,NPL,Asia,29136808
+Netherlands,NL,NLD,Europe,17134873
+New Caledonia,NC,NCL,Oceania,285491
+New Zealand,NZ,NZL,Oceania,4822233
+Nicaragua,NI,NIC,North America,6624554
+Niger,NE,NER,Africa,24206636
+Nigeria,NG,NGA,Africa,206139587
+North Korea,KP,PRK,Asia,25778815
+North Macedonia,MK,MKD,Europe,2083380
+Northern Mariana Islands,MP,MNP,Oceania,57557
+Norway,NO,NOR,Europe,5421242
+Oman,OM,OMN,Asia,5106622
+Pakistan,PK,PAK,Asia,220892331
+Palau,PW,PLW,Oceania,18092
+Palestine,PS,PSE,Asia,5101416
+Panama,PA,PAN,North America,4314768
+Papua New Guinea,PG,PNG,Oceania,8947027
+Paraguay,PY,PRY,South America,7132530
+Peru,PE,PER,South America,32971846
+Philippines,PH,PHL,Asia,109581085
+Poland,PL,POL,Europe,37846605
+Portugal,PT,PRT,Europe,10196707
+Puerto Rico,PR,PRI,North America,2860840
+Qatar,QA,QAT,Asia,2881060
+Romania,RO,ROU,Europe,19237682
+Russia,RU,RUS,Europe,145934460
+Rwanda,RW,RWA,Africa,12952209
+Saint Helena,SH,SHN,Africa,6071
+Saint Kitts and Nevis,KN,KNA,North America,53192
+Saint Lucia,LC,LCA,North America,183629
+Saint Vincent and the Grenadines,VC,VCT,North America,110947
+Samoa,WS,WSM,Oceania,198410
+San Marino,SM,SMR,Europe,33938
+Sao Tome and Principe,ST,STP,Africa,219161
+Saudi Arabia,SA,SAU,Asia,34813867
+Senegal,SN,SEN,Africa,16743930
+Serbia,RS,SRB,Europe,6804596
+Seychelles,SC,SYC,Africa,98340
+Sierra Leone,SL,SLE,Africa,7976985
+Singapore,SG,SGP,Asia,5850343
+Sint Maarten (Dutch part),SX,SXM,North America,42882
+Slovakia,SK,SVK,Europe,5459643
+Slovenia,SI,SVN,Europe,2078932
+Solomon Islands,SB,SLB,Oceania,686878
+Somalia,SO,SOM,Africa,15893219
+South Africa,ZA,ZAF,Africa,59308690
+South Korea,KR,KOR,Asia,51269183
+South Sudan,SS,SSD,Africa,11193729
+Spain,ES,ESP,Europe,46754783
+Sri Lanka,LK,LKA,Asia,21413250
+Sudan,SD,SDN,Africa,43849269
+Suriname,SR,SUR,South America,586634
+Sweden,SE,SWE,Europe,10099270
+Switzerland,CH,CHE,Europe,8654618
+Syria,SY,SYR,Asia,17500657
+Taiwan,TW,TWN,Asia,23816775
+Tajikistan,TJ,TJK,Asia,9537642
+Tanzania,TZ,TZA,Africa,59734213
+Thailand,TH,THA,Asia,69799978
+Timor,TL,TLS,Asia,1318442
+Togo,TG,TGO,Africa,8278737
+Tonga,TO,TON,Oceania,105697
+Trinidad and Tobago,TT,TTO,North America,1399491
+Tunisia,TN,TUN,Africa,11818618
+Turkey,TR,TUR,Asia,84339067
+Turkmenistan,TM,TKM,Asia,6031187
+Turks and Caicos Islands,TC,TCA,North America,38718
+Tuvalu,TV,TUV,Oceania,11792
+Uganda,UG,UGA,Africa,45741000
+Ukraine,UA,UKR,Europe,43733759
+United Arab Emirates,AE,ARE,Asia,9890400
+United Kingdom,GB,GBR,Europe,67886004
+United States,US,USA,North America,331002647
+United States Virgin Islands,VI,VIR,North America,104423
+Uruguay,UY,URY,South America,3473727
+Uzbekistan,UZ,UZB,Asia,33469199
+Vanuatu,VU,VUT,Oceania,307150
+Vatican,VA,VAT,Europe,809
+Venezuela,VE,VEN,South America,28435943
+Vietnam,VN,VNM,Asia,97338583
+Wallis and Futuna,WF,WLF,Oceania,11246
+Western Sahara,EH,ESH,Africa,597330
+Yemen,YE,YEM,Asia,29825968
+Zambia,ZM,ZMB,Africa,18383956
+Zimbabwe,ZW,ZWE,Africa,14862927
+Africa,,OWID_AFR,,1340598113
+Asia,,OWID_ASI,,4639847425
+Europe,,OWID_EUR,,748962983
+European Union,,OWID_EUN,,447189915
+North America,,OWID_NAM,,592072204
+Oceania,,OWID_OCE,,43219954
+South America,,OWID_SAM,,430461090
+World,,OWID_WRL,,7794798729
+`
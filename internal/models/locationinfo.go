@@ -0,0 +1,235 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// The alternative names of the columns of location metadata tables keyed by
+// column. Our World in Data's datasets use the same columns, so they can be
+// read as location metadata tables.
+var locationInfoColumns = map[string][]string{
+	"name":       {"name", "location", "country"},
+	"iso2":       {"iso2", "iso_alpha2"},
+	"iso3":       {"iso3", "iso_code", "iso_alpha3"},
+	"continent":  {"continent"},
+	"population": {"population"},
+}
+
+// LocationInfo types contain metadata about a location.
+type LocationInfo struct {
+
+	// The location's name.
+	Name string `json:"name"`
+
+	// The location's ISO 3166-1 alpha-2 and alpha-3 codes.
+	ISO2 string `json:"iso2,omitempty"`
+	ISO3 string `json:"iso3,omitempty"`
+
+	// The continent the location is on.
+	Continent string `json:"continent,omitempty"`
+
+	// The location's population, or zero if it isn't known.
+	Population int `json:"population,omitempty"`
+}
+
+// LocationTable types contain the metadata of locations. Locations are looked
+// up case-insensitively by name, ISO code or alias.
+type LocationTable struct {
+	infos []*LocationInfo
+	index map[string]*LocationInfo
+}
+
+// MARK: Initializers
+
+// NewLocationTable creates and returns a new location table with the given
+// metadata.
+func NewLocationTable(infos []*LocationInfo) *LocationTable {
+	t := &LocationTable{index: make(map[string]*LocationInfo)}
+	for _, info := range infos {
+		t.add(info)
+	}
+	return t
+}
+
+// DefaultLocationTable creates and returns a new location table of the
+// metadata of countries, territories and Our World in Data's regions.
+func DefaultLocationTable() *LocationTable {
+	t, err := ReadLocationTable(strings.NewReader(defaultLocationInfo), "default location metadata")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ReadLocationTable reads a location table from a CSV file with a header
+// row. The name column is required, and the iso2, iso3, continent and
+// population columns are optional. Rows of a location that has already been
+// read are merged in to it.
+func ReadLocationTable(reader io.Reader, name string) (*LocationTable, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	columns := make(map[string]int)
+	for i, field := range header {
+		field = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(field, "\ufeff")))
+		for column, alternatives := range locationInfoColumns {
+			for _, alternative := range alternatives {
+				if _, ok := columns[column]; !ok && field == alternative {
+					columns[column] = i
+				}
+			}
+		}
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("%s: missing required column \"name\"", name)
+	}
+
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	t := NewLocationTable(nil)
+	for row := 2; ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		info := &LocationInfo{
+			Name:      value(record, "name"),
+			ISO2:      value(record, "iso2"),
+			ISO3:      value(record, "iso3"),
+			Continent: value(record, "continent"),
+		}
+
+		if len(info.Name) == 0 {
+			continue
+		}
+
+		if field := value(record, "population"); len(field) > 0 {
+			if info.Population, err = parseCount(field); err != nil {
+				return nil, fmt.Errorf("%s: row %d: invalid population %q", name, row, field)
+			}
+		}
+
+		t.add(info)
+	}
+
+	return t, nil
+}
+
+// ReadLocationTableFromPath reads the location table in the CSV file at
+// path.
+func ReadLocationTableFromPath(path string) (*LocationTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadLocationTable(file, path)
+}
+
+// MARK: Exported methods
+
+// Len returns the number of locations in the table.
+func (t LocationTable) Len() int {
+	return len(t.infos)
+}
+
+// Infos returns the metadata of every location in the table.
+func (t LocationTable) Infos() []*LocationInfo {
+	return t.infos
+}
+
+// Lookup returns the metadata of the location with the given name, ISO code
+// or alias.
+func (t LocationTable) Lookup(name string) (*LocationInfo, bool) {
+	if info, ok := t.index[normalizeLocationName(name)]; ok {
+		return info, true
+	}
+
+	for _, alias := range LocationAliases(name) {
+		if info, ok := t.index[normalizeLocationName(alias)]; ok {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// Population returns the population of the location with the given name or
+// ISO code, and whether or not it is known.
+func (t LocationTable) Population(name string) (int, bool) {
+	if info, ok := t.Lookup(name); ok && info.Population > 0 {
+		return info.Population, true
+	}
+	return 0, false
+}
+
+// Merge merges the metadata of other in to the table. The non-empty fields
+// of locations that are in both tables replace the table's.
+func (t *LocationTable) Merge(other *LocationTable) {
+	for _, info := range other.infos {
+		copied := *info
+		t.add(&copied)
+	}
+}
+
+// MARK: Unexported methods
+
+// add adds the metadata to the table, or merges it in to the metadata of the
+// location with the same name.
+func (t *LocationTable) add(info *LocationInfo) {
+	existing, ok := t.index[strings.ToLower(info.Name)]
+	if !ok || !strings.EqualFold(existing.Name, info.Name) {
+		t.infos = append(t.infos, info)
+		t.indexInfo(info)
+		return
+	}
+
+	if len(info.ISO2) > 0 {
+		existing.ISO2 = info.ISO2
+	}
+
+	if len(info.ISO3) > 0 {
+		existing.ISO3 = info.ISO3
+	}
+
+	if len(info.Continent) > 0 {
+		existing.Continent = info.Continent
+	}
+
+	if info.Population > 0 {
+		existing.Population = info.Population
+	}
+
+	t.indexInfo(existing)
+}
+
+// indexInfo indexes the metadata by name and ISO codes. Names take
+// precedence over codes.
+func (t *LocationTable) indexInfo(info *LocationInfo) {
+	for _, code := range []string{info.ISO2, info.ISO3} {
+		code = strings.ToLower(code)
+		if existing, ok := t.index[code]; len(code) > 0 && (!ok || !strings.EqualFold(existing.Name, code)) {
+			t.index[code] = info
+		}
+	}
+
+	t.index[strings.ToLower(info.Name)] = info
+}
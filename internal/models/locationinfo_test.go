@@ -0,0 +1,166 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadLocationTable(t *testing.T) {
+	data := "iso_code,continent,location,population\n" +
+		"ITA,Europe,Italy,60461828\n" +
+		"ESP,Europe,Spain,\n" +
+		",,,\n" +
+		"ESP,,spain,46754783\n"
+
+	table, err := ReadLocationTable(strings.NewReader(data), "owid.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*LocationInfo{
+		{Name: "Italy", ISO3: "ITA", Continent: "Europe", Population: 60461828},
+		{Name: "Spain", ISO3: "ESP", Continent: "Europe", Population: 46754783},
+	}
+	if !reflect.DeepEqual(table.Infos(), want) {
+		t.Errorf("infos = %v, want %v", table.Infos(), want)
+	}
+
+	errors := []struct {
+		name string
+		data string
+	}{
+		{"missing name", "iso3,population\nITA,60461828\n"},
+		{"invalid population", "name,population\nItaly,many\n"},
+		{"empty", ""},
+	}
+
+	for _, test := range errors {
+		if _, err := ReadLocationTable(strings.NewReader(test.data), "info.csv"); err == nil {
+			t.Errorf("%s: ReadLocationTable succeeded, want an error", test.name)
+		}
+	}
+}
+
+func TestLocationTableLookup(t *testing.T) {
+	table := DefaultLocationTable()
+
+	tests := []struct {
+		name       string
+		want       string
+		population int
+	}{
+		{"Italy", "Italy", 60461828},
+		{"italy", "Italy", 60461828},
+		{"ITA", "Italy", 60461828},
+		{"it", "Italy", 60461828},
+		{"US", "United States", 331002647},
+		{"United States of America", "United States", 331002647},
+		{"Korea, South", "South Korea", 51269183},
+		{"Atlantis", "", 0},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if info, ok := table.Lookup(test.name); ok {
+			got = info.Name
+		}
+		if got != test.want {
+			t.Errorf("Lookup(%q) = %q, want %q", test.name, got, test.want)
+		}
+
+		if population, ok := table.Population(test.name); population != test.population || ok != (test.population > 0) {
+			t.Errorf("Population(%q) = %d, %v, want %d", test.name, population, ok, test.population)
+		}
+	}
+
+	// Overrides replace the non-empty fields of the defaults
+	table.Merge(NewLocationTable([]*LocationInfo{{Name: "italy", Population: 59000000}, {Name: "Atlantis", Continent: "Atlantic"}}))
+
+	if info, _ := table.Lookup("ITA"); info.Population != 59000000 || info.Continent != "Europe" {
+		t.Errorf("merged Italy = %+v", info)
+	}
+	if _, ok := table.Lookup("Atlantis"); !ok {
+		t.Errorf("merged Atlantis isn't in the table")
+	}
+	if _, ok := table.Population("Atlantis"); ok {
+		t.Errorf("Atlantis has a population")
+	}
+}
+
+func TestPerMillion(t *testing.T) {
+	date := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := func(name string, cases []int, population int) []*COVRecord {
+		var records []*COVRecord
+		for i, c := range cases {
+			record := &COVRecord{Date: date.AddDate(0, 0, i), Location: name, TotalCases: c}
+			if population > 0 {
+				record.SetValue(MetricPopulation, population)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	world := NewWorldFromLocations([]*Location{
+		NewLocation("Italy", records("Italy", []int{100, 200}, 0)),
+		NewLocation("Luxembourg", records("Luxembourg", []int{10, 40}, 500000)),
+		NewLocation("Atlantis", records("Atlantis", []int{1000, 2000}, 0)),
+	})
+	world.JoinLocationInfo(NewLocationTable([]*LocationInfo{
+		{Name: "Italy", Population: 2000000},
+		{Name: "Luxembourg", Continent: "Europe"},
+		{Name: "World", Population: 10000000},
+	}))
+
+	tests := []struct {
+		location   string
+		population int
+		value      float64
+		signal     []float64
+	}{
+		{"Italy", 2000000, 100, []float64{50, 100}},
+		{"Luxembourg", 500000, 80, []float64{20, 80}},
+		{"Atlantis", 0, 0, nil},
+	}
+
+	for _, test := range tests {
+		l := world.Location(test.location)
+		if population := l.Population(); population != test.population {
+			t.Errorf("%s population = %d, want %d", test.location, population, test.population)
+		}
+
+		value, ok := l.PerMillion(MetricTotalCases)
+		if value != test.value || ok != (test.population > 0) {
+			t.Errorf("%s per million = %v, %v, want %v", test.location, value, ok, test.value)
+		}
+
+		if signal := world.PerMillionSignalForLocation(test.location, MetricTotalCases, MissingZero); !reflect.DeepEqual(signal, test.signal) {
+			t.Errorf("%s per million signal = %v, want %v", test.location, signal, test.signal)
+		}
+	}
+
+	if signal := world.PerMillionSignal(MetricTotalCases, MissingZero); !reflect.DeepEqual(signal, []float64{111, 224}) {
+		t.Errorf("world per million signal = %v", signal)
+	}
+
+	world.SortPerMillion(MetricTotalCases.String(), "desc")
+
+	var names []string
+	for _, l := range world.Locations {
+		names = append(names, l.Name)
+	}
+	if want := []string{"Atlantis", "Luxembourg", "Italy"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("sorted per million = %v, want %v", names, want)
+	}
+
+	missing := &COVRecord{}
+	missing.SetMissing(MetricTotalCases)
+	if value := missing.FormatPerMillion(MetricTotalCases, 1000000); value != "n/a" {
+		t.Errorf("missing value per million = %q, want n/a", value)
+	}
+	if value := (&COVRecord{TotalCases: 3}).FormatPerMillion(MetricTotalCases, 2000000); value != "1.50" {
+		t.Errorf("value per million = %q, want 1.50", value)
+	}
+}
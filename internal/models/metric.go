@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric types identify a value of a record.
+type Metric int
+
+// The metrics of a record.
+const (
+	MetricNewCases Metric = iota
+	MetricNewDeaths
+	MetricTotalCases
+	MetricTotalDeaths
+	MetricNewTests
+	MetricTotalTests
+	MetricHospPatients
+	MetricICUPatients
+	MetricNewVaccinations
+	MetricTotalVaccinations
+	MetricPeopleVaccinated
+	MetricPeopleFullyVaccinated
+	MetricPopulation
+)
+
+// metricInfo types describe a metric.
+type metricInfo struct {
+
+	// The name used to select the metric on the command line.
+	name string
+
+	// The title used in output headers.
+	title string
+
+	// The name of the metric's column in CSV datasets.
+	column string
+
+	// Whether or not the metric accumulates over time, so that its last
+	// reported value still applies on days without a report.
+	cumulative bool
+}
+
+// The description of each metric indexed by metric.
+var metrics = []metricInfo{
+	MetricNewCases:              {"newCases", "New Cases", "new_cases", false},
+	MetricNewDeaths:             {"newDeaths", "New Deaths", "new_deaths", false},
+	MetricTotalCases:            {"totalCases", "Total Cases", "total_cases", true},
+	MetricTotalDeaths:           {"totalDeaths", "Total Deaths", "total_deaths", true},
+	MetricNewTests:              {"newTests", "New Tests", "new_tests", false},
+	MetricTotalTests:            {"totalTests", "Total Tests", "total_tests", true},
+	MetricHospPatients:          {"hospPatients", "Hospitalised", "hosp_patients", false},
+	MetricICUPatients:           {"icuPatients", "ICU Patients", "icu_patients", false},
+	MetricNewVaccinations:       {"newVaccinations", "New Vaccs", "new_vaccinations", false},
+	MetricTotalVaccinations:     {"totalVaccinations", "Total Vaccs", "total_vaccinations", true},
+	MetricPeopleVaccinated:      {"peopleVaccinated", "Vaccinated", "people_vaccinated", true},
+	MetricPeopleFullyVaccinated: {"peopleFullyVaccinated", "Fully Vaccd", "people_fully_vaccinated", true},
+	MetricPopulation:            {"population", "Population", "population", true},
+}
+
+// DefaultMetrics are the metrics that every dataset provides.
+var DefaultMetrics = []Metric{
+	MetricNewCases,
+	MetricNewDeaths,
+	MetricTotalCases,
+	MetricTotalDeaths,
+}
+
+// MetricSet types contain a set of metrics.
+type MetricSet uint32
+
+// MARK: Initializers
+
+// ParseMetric returns the metric with the given case-insensitive name.
+func ParseMetric(name string) (Metric, error) {
+	for i, info := range metrics {
+		if strings.ToLower(info.name) == strings.ToLower(name) {
+			return Metric(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown metric %q, expected one of %s", name, strings.Join(MetricNames(), ", "))
+}
+
+// ParseMetrics returns the metrics in the given comma separated list of
+// names.
+func ParseMetrics(names string) ([]Metric, error) {
+	var parsed []Metric
+	for _, name := range strings.Split(names, ",") {
+		metric, err := ParseMetric(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, metric)
+	}
+	return parsed, nil
+}
+
+// MARK: Exported functions
+
+// AllMetrics returns the set of every metric.
+func AllMetrics() MetricSet {
+	return MetricSet(1<<uint(len(metrics)) - 1)
+}
+
+// Metrics returns every metric in order.
+func Metrics() []Metric {
+	list := make([]Metric, len(metrics))
+	for i := range metrics {
+		list[i] = Metric(i)
+	}
+	return list
+}
+
+// MetricNames returns the names of every metric.
+func MetricNames() []string {
+	names := make([]string, len(metrics))
+	for i, info := range metrics {
+		names[i] = info.name
+	}
+	return names
+}
+
+// MARK: Exported methods
+
+// Name returns the name used to select the metric.
+func (m Metric) Name() string {
+	return metrics[m].name
+}
+
+// Title returns the title used in output headers.
+func (m Metric) Title() string {
+	return metrics[m].title
+}
+
+// Column returns the name of the metric's column in CSV datasets.
+func (m Metric) Column() string {
+	return metrics[m].column
+}
+
+// Cumulative returns whether or not the metric accumulates over time.
+func (m Metric) Cumulative() bool {
+	return metrics[m].cumulative
+}
+
+// Has returns whether or not the set contains the metric.
+func (s MetricSet) Has(metric Metric) bool {
+	return s&(1<<uint(metric)) != 0
+}
+
+// With returns the set with the given metrics added.
+func (s MetricSet) With(metrics ...Metric) MetricSet {
+	for _, metric := range metrics {
+		s |= 1 << uint(metric)
+	}
+	return s
+}
+
+// Without returns the set with the given metrics removed.
+func (s MetricSet) Without(metrics ...Metric) MetricSet {
+	for _, metric := range metrics {
+		s &^= 1 << uint(metric)
+	}
+	return s
+}
+
+// MARK: String interface methods
+
+func (m Metric) String() string {
+	return m.Name()
+}
+
+// MARK: TextMarshaler interface methods
+
+// MarshalText encodes the metric as its name.
+func (m Metric) MarshalText() ([]byte, error) {
+	return []byte(m.Name()), nil
+}
+
+// UnmarshalText decodes the metric from its name.
+func (m *Metric) UnmarshalText(text []byte) error {
+	metric, err := ParseMetric(string(text))
+	if err != nil {
+		return err
+	}
+	*m = metric
+	return nil
+}
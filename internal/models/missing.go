@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingPolicy types describe how missing values are treated when a signal
+// is created from records.
+type MissingPolicy int
+
+// The missing value policies.
+const (
+	// MissingZero treats missing values as zero.
+	MissingZero MissingPolicy = iota
+
+	// MissingSkip leaves missing values out of the signal.
+	MissingSkip
+
+	// MissingForwardFill replaces missing values with the last reported
+	// value, or zero before the first report.
+	MissingForwardFill
+
+	// MissingInterpolate replaces missing values by linearly interpolating
+	// between the surrounding reported values. Missing values before the
+	// first or after the last report take the nearest reported value.
+	MissingInterpolate
+)
+
+// The names of the missing value policies indexed by policy.
+var missingPolicyNames = []string{
+	MissingZero:        "zero",
+	MissingSkip:        "skip",
+	MissingForwardFill: "forwardFill",
+	MissingInterpolate: "interpolate",
+}
+
+// MARK: Initializers
+
+// ParseMissingPolicy returns the missing value policy with the given
+// case-insensitive name.
+func ParseMissingPolicy(name string) (MissingPolicy, error) {
+	for i, policyName := range missingPolicyNames {
+		if strings.ToLower(policyName) == strings.ToLower(name) {
+			return MissingPolicy(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown missing value policy %q, expected one of %s", name, strings.Join(missingPolicyNames, ", "))
+}
+
+// MARK: Exported functions
+
+// MissingPolicyNames returns the names of every missing value policy.
+func MissingPolicyNames() []string {
+	return append([]string(nil), missingPolicyNames...)
+}
+
+// MARK: String interface methods
+
+func (p MissingPolicy) String() string {
+	return missingPolicyNames[p]
+}
+
+// MARK: Unexported functions
+
+// recordsSignal returns the records' values of the given metric as a float
+// slice with missing values treated according to policy.
+func recordsSignal(records []*COVRecord, metric Metric, policy MissingPolicy) []float64 {
+	return lookupSignal(len(records), func(i int) (int, bool) {
+		return records[i].Lookup(metric)
+	}, policy)
+}
+
+// lookupSignal returns the n values returned by lookup as a float slice with
+// missing values treated according to policy.
+func lookupSignal(n int, lookup func(i int) (int, bool), policy MissingPolicy) []float64 {
+	signal := make([]float64, 0, n)
+	valid := make([]bool, 0, n)
+
+	for i := 0; i < n; i++ {
+		value, ok := lookup(i)
+		if !ok && policy == MissingSkip {
+			continue
+		}
+
+		signal = append(signal, float64(value))
+		valid = append(valid, ok)
+	}
+
+	return fillSignal(signal, valid, policy)
+}
+
+// fillSignal replaces the signal's values that aren't valid according to
+// policy and returns the signal. The skip policy leaves them as they are.
+func fillSignal(signal []float64, valid []bool, policy MissingPolicy) []float64 {
+	switch policy {
+	case MissingZero:
+		for i := range signal {
+			if !valid[i] {
+				signal[i] = 0
+			}
+		}
+	case MissingForwardFill:
+		last := 0.0
+		for i := range signal {
+			if valid[i] {
+				last = signal[i]
+			} else {
+				signal[i] = last
+			}
+		}
+	case MissingInterpolate:
+		previous := -1
+		for i := range signal {
+			if !valid[i] {
+				continue
+			}
+
+			if previous < 0 {
+				for j := 0; j < i; j++ {
+					signal[j] = signal[i]
+				}
+			} else {
+				step := (signal[i] - signal[previous]) / float64(i-previous)
+				for j := previous + 1; j < i; j++ {
+					signal[j] = signal[previous] + step*float64(j-previous)
+				}
+			}
+
+			previous = i
+		}
+
+		if previous >= 0 {
+			for j := previous + 1; j < len(signal); j++ {
+				signal[j] = signal[previous]
+			}
+		}
+	}
+
+	return signal
+}
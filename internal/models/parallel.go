@@ -0,0 +1,183 @@
+package models
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// The size in bytes of the smallest dataset that is parsed in parallel.
+const parallelParseThreshold = 1 << 20
+
+// The number of chunks each worker parses on average. More chunks than
+// workers balance the load when some chunks take longer than others.
+const chunksPerWorker = 4
+
+// chunkResult types contain the results of parsing a chunk of a dataset.
+type chunkResult struct {
+	records      []*COVRecord
+	fingerprints []Fingerprint
+	diagnostics  []Diagnostic
+	err          error
+}
+
+// MARK: Unexported functions
+
+// readRecords reads every record of the CSV dataset at path. Large datasets
+// are split in to chunks of whole rows that are parsed in parallel, and the
+// records and diagnostics are returned in the order of the file either way.
+//
+// If index isn't nil, the fingerprints of the records' rows are returned and
+// rows whose fingerprints are in the index reuse its records instead of
+// being parsed.
+func readRecords(path string, options *ParseOptions, index rowIndex) ([]*COVRecord, []Fingerprint, []Diagnostic, error) {
+	workers := runtime.NumCPU()
+	if options != nil && options.Workers > 0 {
+		workers = options.Workers
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if workers < 2 || info.Size() < parallelParseThreshold {
+		return readRecordsSequentially(path, options, index)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return parseChunks(data, path, options, index, workers)
+}
+
+// readRecordsSequentially reads every record of the CSV dataset at path in
+// one goroutine.
+func readRecordsSequentially(path string, options *ParseOptions, index rowIndex) ([]*COVRecord, []Fingerprint, []Diagnostic, error) {
+	reader, err := OpenRecordReader(path, options, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer reader.Close()
+
+	result := readChunk(reader, index)
+	return result.records, result.fingerprints, result.diagnostics, result.err
+}
+
+// parseChunks parses the CSV dataset in data with the given number of
+// workers.
+func parseChunks(data []byte, name string, options *ParseOptions, index rowIndex, workers int) ([]*COVRecord, []Fingerprint, []Diagnostic, error) {
+	// Map the columns of the header row
+	headerEnd, quotes := nextRowEnd(data, 0, 0)
+	headerReader, err := NewRecordReader(bytes.NewReader(data[:headerEnd]), name, options, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Split the rows in to chunks and note the line each chunk starts after
+	var chunks [][]byte
+	var offsets []int
+
+	size := (len(data)-headerEnd)/(workers*chunksPerWorker) + 1
+	offset := bytes.Count(data[:headerEnd], []byte{'\n'})
+
+	for start := headerEnd; start < len(data); {
+		end := len(data)
+		if target := start + size; target < len(data) {
+			quotes += bytes.Count(data[start:target], []byte{'"'})
+			end, quotes = nextRowEnd(data, target, quotes)
+		}
+
+		chunks = append(chunks, data[start:end])
+		offsets = append(offsets, offset)
+
+		offset += bytes.Count(data[start:end], []byte{'\n'})
+		start = end
+	}
+
+	// Parse the chunks
+	results := make([]chunkResult, len(chunks))
+	indices := make(chan int)
+
+	var group sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for i := range indices {
+				csvReader, lines := newCSVReader(bytes.NewReader(chunks[i]))
+				reader := newRecordReader(csvReader, lines, name, headerReader.header, options, nil, offsets[i])
+				results[i] = readChunk(reader, index)
+			}
+		}()
+	}
+
+	for i := range chunks {
+		indices <- i
+	}
+	close(indices)
+	group.Wait()
+
+	// Merge the chunks in order. The first error of the file is in the first
+	// chunk that failed.
+	var records []*COVRecord
+	var fingerprints []Fingerprint
+	var diagnostics []Diagnostic
+
+	for _, result := range results {
+		if result.err != nil {
+			return nil, nil, nil, result.err
+		}
+
+		records = append(records, result.records...)
+		fingerprints = append(fingerprints, result.fingerprints...)
+		diagnostics = append(diagnostics, result.diagnostics...)
+	}
+
+	return records, fingerprints, diagnostics, nil
+}
+
+// readChunk reads every record of the reader, reusing the records of the
+// index if it isn't nil.
+func readChunk(reader *RecordReader, index rowIndex) chunkResult {
+	reader.index = index
+
+	result := chunkResult{}
+	for reader.Next() {
+		result.records = append(result.records, reader.Record())
+		if index != nil {
+			result.fingerprints = append(result.fingerprints, reader.fingerprint)
+		}
+	}
+
+	result.diagnostics = reader.Diagnostics()
+	result.err = reader.Err()
+	return result
+}
+
+// nextRowEnd returns the offset after the first line break at or after from
+// that isn't inside of a quoted field, and the number of quotes before that
+// offset. The quotes are the number of quotes before from.
+//
+// Quotes inside of quoted fields are escaped by doubling them, so a line
+// break is inside of a quoted field when an odd number of quotes precede it.
+func nextRowEnd(data []byte, from int, quotes int) (int, int) {
+	end := from
+	for {
+		i := bytes.IndexByte(data[end:], '\n')
+		if i < 0 {
+			return len(data), quotes
+		}
+
+		quotes += bytes.Count(data[end:end+i], []byte{'"'})
+		end += i + 1
+
+		if quotes%2 == 0 {
+			return end, quotes
+		}
+	}
+}
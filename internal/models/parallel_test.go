@@ -0,0 +1,145 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseIsDeterministic(t *testing.T) {
+	data, lines := largeDataset(400, 365, true)
+	if len(data) < parallelParseThreshold {
+		t.Fatalf("expected the dataset to be parsed in parallel, but it is only %d bytes", len(data))
+	}
+	path := writeDataset(t, string(data))
+
+	var sequential *World
+	for _, workers := range []int{1, 2, runtime.NumCPU()} {
+		world, err := NewWorldFromPathWithOptions(path, &ParseOptions{Lenient: true, Workers: workers})
+		if err != nil {
+			t.Fatalf("%d workers: %v", workers, err)
+		}
+
+		if len(world.Diagnostics) != len(lines) {
+			t.Fatalf("%d workers: expected %d diagnostics, got %d", workers, len(lines), len(world.Diagnostics))
+		}
+
+		for i, diagnostic := range world.Diagnostics {
+			if diagnostic.Line != lines[i] {
+				t.Errorf("%d workers: expected diagnostic %d on line %d, got %d", workers, i, lines[i], diagnostic.Line)
+			}
+		}
+
+		if sequential == nil {
+			sequential = world
+		} else if !reflect.DeepEqual(world, sequential) {
+			t.Errorf("%d workers: expected the same world as the sequential parse", workers)
+		}
+	}
+}
+
+func TestDiagnosticLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		line int
+	}{
+		{"first row", "date,location,new_cases,new_deaths,total_cases,total_deaths\nbad,Italy,1,0,1,0\n", 2},
+		{"after blank lines", "date,location,new_cases,new_deaths,total_cases,total_deaths\n\n2020-03-01,Italy,1,0,1,0\n\n\nbad,Italy,1,0,1,0\n", 6},
+		{"after a quoted line break", "date,location,new_cases,new_deaths,total_cases,total_deaths\n2020-03-01,\"Italy\nnorth\",1,0,1,0\nbad,Italy,1,0,1,0\n", 4},
+		{"spanning lines", "date,location,new_cases,new_deaths,total_cases,total_deaths\n\nbad,\"Italy\nnorth\",1,0,1,0\n", 3},
+		{"without a final line break", "date,location,new_cases,new_deaths,total_cases,total_deaths\n2020-03-01,Italy,1,0,1,0\n\nbad,Italy,1,0,1,0", 4},
+		{"wrong column count", "date,location,new_cases,new_deaths,total_cases,total_deaths\n\n2020-03-01,Italy,1\n", 3},
+		{"crlf line breaks", "date,location,new_cases,new_deaths,total_cases,total_deaths\r\n\r\n2020-03-01,Italy,1,0,1,0\r\nbad,Italy,1,0,1,0\r\n", 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, sequential, err := readRecordsSequentially(writeDataset(t, test.data), &ParseOptions{Lenient: true}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, parallel, err := parseChunks([]byte(test.data), "", &ParseOptions{Lenient: true}, nil, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, diagnostics := range [][]Diagnostic{sequential, parallel} {
+				if len(diagnostics) != 1 || diagnostics[0].Line != test.line {
+					t.Errorf("expected one diagnostic on line %d, got %+v", test.line, diagnostics)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	data, _ := largeDataset(220, 900, false)
+	path := writeDataset(b, string(data))
+
+	parallel := runtime.NumCPU()
+	if parallel < 2 {
+		parallel = 2
+	}
+
+	for _, workers := range []int{1, parallel} {
+		name := "sequential"
+		if workers > 1 {
+			name = fmt.Sprintf("parallel-%d", workers)
+		}
+
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := NewWorldFromPathWithOptions(path, &ParseOptions{Workers: workers}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// largeDataset returns an OWID-layout dataset of the given number of
+// locations and days. If problems is true, blank lines, location names with
+// quoted line breaks and rows that can't be parsed are mixed in, and the
+// lines of the rows that can't be parsed are returned.
+func largeDataset(locations int, days int, problems bool) ([]byte, []int) {
+	var buffer bytes.Buffer
+	buffer.WriteString("date,location,new_cases,new_deaths,total_cases,total_deaths\n")
+
+	line := 1
+	var lines []int
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for l := 0; l < locations; l++ {
+		name := fmt.Sprintf("Location %03d", l)
+		if problems && l%10 == 0 {
+			name = fmt.Sprintf("\"Location\n%03d\"", l)
+		}
+
+		totalCases := 0
+		for d := 0; d < days; d++ {
+			if problems && (l+d)%101 == 0 {
+				buffer.WriteString("\n")
+				line++
+			}
+
+			date := start.AddDate(0, 0, d).Format("2006-01-02")
+			if problems && (l*days+d)%997 == 0 {
+				date = "not a date"
+				lines = append(lines, line+1)
+			}
+
+			newCases := (l + d) % 97
+			totalCases += newCases
+			fmt.Fprintf(&buffer, "%s,%s,%d,%d,%d,%d\n", date, name, newCases, newCases%7, totalCases, totalCases/10)
+			line += 1 + bytes.Count([]byte(name), []byte{'\n'})
+		}
+	}
+
+	return buffer.Bytes(), lines
+}
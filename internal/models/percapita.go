@@ -0,0 +1,35 @@
+package models
+
+// MARK: Exported functions
+
+// PerMillion returns the value per million people of the given population.
+func PerMillion(value int, population int) float64 {
+	return float64(value) * 1000000 / float64(population)
+}
+
+// MARK: Unexported functions
+
+// perMillionSignal returns the signal's values per million people of the
+// given population, or nil if the population isn't known.
+func perMillionSignal(signal []float64, population int) []float64 {
+	if population <= 0 {
+		return nil
+	}
+
+	scaled := make([]float64, len(signal))
+	for i, value := range signal {
+		scaled[i] = value * 1000000 / float64(population)
+	}
+	return scaled
+}
+
+// latestPopulation returns the latest population reported by the records, or
+// zero if none of them reported one.
+func latestPopulation(records []*COVRecord) int {
+	for i := len(records) - 1; i >= 0; i-- {
+		if population, ok := records[i].Lookup(MetricPopulation); ok && population > 0 {
+			return population
+		}
+	}
+	return 0
+}
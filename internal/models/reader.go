@@ -0,0 +1,425 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// RecordFilter types select the records read by a record reader. Filters
+// are applied to the raw fields of each row, so rows that aren't selected
+// are never parsed.
+type RecordFilter struct {
+
+	// The case-insensitive name of the location to read. Every location is
+	// read if it is empty.
+	Location string
+
+	// Other case-insensitive names of the location, such as its aliases.
+	// Rows with any of the names are read.
+	Aliases []string
+
+	// The first date to read. Dates aren't bounded below if it is zero.
+	From time.Time
+
+	// The last date to read. Dates aren't bounded above if it is zero.
+	To time.Time
+}
+
+// RecordReader types read the records of a CSV dataset one at a time, so
+// that datasets can be processed without loading them in to memory.
+//
+// Use Next to advance to each record, Record to get it and Err to check for
+// an error once Next returns false:
+//
+//	reader, err := models.OpenRecordReader(path, nil, nil)
+//	...
+//	defer reader.Close()
+//
+//	for reader.Next() {
+//		record := reader.Record()
+//		...
+//	}
+//	if err := reader.Err(); err != nil {
+//		...
+//	}
+type RecordReader struct {
+	name      string
+	closer    io.Closer
+	csvReader *csv.Reader
+	lines     *lineCounter
+	header    *Header
+	lenient   bool
+
+	// The filter's normalized location names and the raw bounds of its
+	// dates.
+	locations map[string]bool
+	from      string
+	to        string
+
+	lineOffset  int
+	index       rowIndex
+	fingerprint Fingerprint
+	record      *COVRecord
+	err         error
+	diagnostics []Diagnostic
+}
+
+// RowReader types read the rows of CSV files with their own layout, such as
+// the files of data sources, along with the line each row starts on, so that
+// problems with rows can be reported as diagnostics.
+type RowReader struct {
+	csvReader *csv.Reader
+	lines     *lineCounter
+	line      int
+}
+
+// lineCounter types count the lines of the input of a CSV reader, so that
+// rows are reported by the line of the input they start on. Lines that the
+// CSV reader skips, such as blank lines, are counted too.
+type lineCounter struct {
+	reader io.Reader
+
+	// The buffer that the CSV reader reads from.
+	buffer *bufio.Reader
+
+	// The number of line breaks read from reader, and the last byte read.
+	breaks int
+	last   byte
+}
+
+// MARK: Initializers
+
+// OpenRecordReader opens the CSV dataset at path and returns a new record
+// reader that reads the records selected by filter. A nil filter selects
+// every record. The reader must be closed.
+func OpenRecordReader(path string, options *ParseOptions, filter *RecordFilter) (*RecordReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewRecordReader(file, path, options, filter)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	reader.closer = file
+	return reader, nil
+}
+
+// NewRecordReader creates and returns a new record reader that reads the
+// records selected by filter from the CSV dataset in reader. The name is
+// used in diagnostics. A nil filter selects every record.
+//
+// Columns are mapped by the names in the dataset's header row, which is
+// read immediately.
+func NewRecordReader(reader io.Reader, name string, options *ParseOptions, filter *RecordFilter) (*RecordReader, error) {
+	csvReader, lines := newCSVReader(reader)
+
+	headerRecord, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read header: %v", name, err)
+	}
+
+	header := NewHeader(headerRecord, options)
+	if err = header.Require(RequiredColumns()...); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return newRecordReader(csvReader, lines, name, header, options, filter, 0), nil
+}
+
+// NewRowReader creates and returns a new row reader of the CSV input in
+// reader. Rows may have any number of fields.
+func NewRowReader(reader io.Reader) *RowReader {
+	csvReader, lines := newCSVReader(reader)
+	return &RowReader{csvReader: csvReader, lines: lines}
+}
+
+// newRecordReader creates and returns a new record reader of the rows read
+// by csvReader, whose input's lines are counted by lines and whose columns
+// are mapped by header. The offset is the number of lines of the dataset
+// before csvReader's input.
+func newRecordReader(csvReader *csv.Reader, lines *lineCounter, name string, header *Header, options *ParseOptions, filter *RecordFilter, offset int) *RecordReader {
+	recordReader := &RecordReader{
+		name:       name,
+		csvReader:  csvReader,
+		lines:      lines,
+		header:     header,
+		lenient:    options != nil && options.Lenient,
+		lineOffset: offset,
+	}
+
+	if filter != nil {
+		recordReader.locations = filter.names()
+		if !filter.From.IsZero() {
+			recordReader.from = filter.From.Format("2006-01-02")
+		}
+		if !filter.To.IsZero() {
+			recordReader.to = filter.To.Format("2006-01-02")
+		}
+	}
+
+	return recordReader
+}
+
+// MARK: Exported methods
+
+// Selects returns whether or not the filter selects the record.
+func (f RecordFilter) Selects(record *COVRecord) bool {
+	if names := f.names(); names != nil && !names[normalizeLocationName(record.Location)] {
+		return false
+	}
+
+	date := record.Date.Format("2006-01-02")
+	if !f.From.IsZero() && date < f.From.Format("2006-01-02") {
+		return false
+	}
+	if !f.To.IsZero() && date > f.To.Format("2006-01-02") {
+		return false
+	}
+
+	return true
+}
+
+// Next advances the reader to the next selected record and returns whether
+// or not there is one.
+//
+// Rows that can't be parsed stop the reader with a Diagnostic error. When
+// parsing leniently those rows are skipped and their diagnostics are
+// collected instead. Errors reading the underlying input always stop the
+// reader.
+func (r *RecordReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	previous := r.record
+	r.record = nil
+
+	for {
+		record, readError := r.csvReader.Read()
+
+		if readError != nil {
+			if readError == io.EOF {
+				return false
+			}
+
+			// Only malformed rows can be skipped, other errors persist
+			parseError, ok := readError.(*csv.ParseError)
+			if !ok {
+				r.err = fmt.Errorf("%s: %v", r.name, readError)
+				return false
+			}
+
+			diagnostic := Diagnostic{File: r.name, Line: r.lineOffset + parseError.Line, Message: parseError.Err.Error()}
+			if !r.report(diagnostic) {
+				return false
+			}
+			continue
+		}
+
+		if len(record) != r.header.Len() {
+			ok := r.report(Diagnostic{
+				File:    r.name,
+				Line:    r.lineOffset + r.lines.rowLine(record),
+				Message: fmt.Sprintf("expected %d columns but found %d", r.header.Len(), len(record)),
+			})
+			if !ok {
+				return false
+			}
+			continue
+		}
+
+		if !r.selects(record) {
+			continue
+		}
+
+		// Reuse the indexed records of unchanged rows
+		var covRecord *COVRecord
+		if r.index != nil {
+			r.fingerprint = fingerprintRow(record)
+			covRecord = r.index.lookup(r.fingerprint)
+		}
+
+		var err error
+		if covRecord == nil {
+			covRecord, err = NewCOVRecord(r.header, record)
+		}
+
+		if err != nil {
+			diagnostic := Diagnostic{File: r.name, Line: r.lineOffset + r.lines.rowLine(record), Message: err.Error()}
+			if fieldError, ok := err.(*FieldError); ok {
+				diagnostic.Column = fieldError.Column
+				diagnostic.Value = fieldError.Value
+				diagnostic.Message = fieldError.Err.Error()
+			}
+
+			if !r.report(diagnostic) {
+				return false
+			}
+			continue
+		}
+
+		// Share one copy of the location's name between its records
+		if previous != nil && previous.Location == covRecord.Location {
+			covRecord.Location = previous.Location
+		}
+
+		r.record = covRecord
+		return true
+	}
+}
+
+// Record returns the current record.
+func (r *RecordReader) Record() *COVRecord {
+	return r.record
+}
+
+// Err returns the error that stopped the reader, if any.
+func (r *RecordReader) Err() error {
+	return r.err
+}
+
+// Diagnostics returns the problems with the rows that were skipped while
+// reading leniently.
+func (r *RecordReader) Diagnostics() []Diagnostic {
+	return r.diagnostics
+}
+
+// Close closes the file of readers created with OpenRecordReader.
+func (r *RecordReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+// Read reads the next row, or returns io.EOF at the end of the input. The
+// returned slice may be reused by the next call.
+//
+// Rows that can't be parsed, such as rows with a bare quote, return a
+// *csv.ParseError and reading can continue with the next row. Other errors,
+// such as errors reading the input, stop reading.
+func (r *RowReader) Read() ([]string, error) {
+	record, err := r.csvReader.Read()
+	if parseError, ok := err.(*csv.ParseError); ok {
+		r.line = parseError.Line
+		return nil, parseError
+	} else if err != nil {
+		return nil, err
+	}
+
+	r.line = r.lines.rowLine(record)
+	return record, nil
+}
+
+// Line returns the line that the last row read starts on, or of the problem
+// with it if it couldn't be read.
+func (r *RowReader) Line() int {
+	return r.line
+}
+
+// MARK: Unexported methods
+
+// names returns the filter's normalized location names, or nil if it reads
+// every location.
+func (f RecordFilter) names() map[string]bool {
+	if len(f.Location) == 0 {
+		return nil
+	}
+
+	names := map[string]bool{normalizeLocationName(f.Location): true}
+	for _, alias := range f.Aliases {
+		names[normalizeLocationName(alias)] = true
+	}
+	return names
+}
+
+// report reports the diagnostic and returns whether or not reading can
+// continue.
+func (r *RecordReader) report(diagnostic Diagnostic) bool {
+	if !r.lenient {
+		r.err = diagnostic
+		return false
+	}
+
+	r.diagnostics = append(r.diagnostics, diagnostic)
+	return true
+}
+
+// selects returns whether or not the filter selects the raw row.
+func (r *RecordReader) selects(record []string) bool {
+	if r.locations != nil && !r.locations[normalizeLocationName(r.header.value(record, "location"))] {
+		return false
+	}
+
+	if len(r.from) > 0 || len(r.to) > 0 {
+		date := r.header.value(record, "date")
+		if len(r.from) > 0 && date < r.from {
+			return false
+		}
+		if len(r.to) > 0 && date > r.to {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MARK: Unexported functions
+
+// newCSVReader creates and returns a new CSV reader of datasets and the
+// counter of its input's lines.
+func newCSVReader(reader io.Reader) (*csv.Reader, *lineCounter) {
+	lines := &lineCounter{reader: reader}
+	lines.buffer = bufio.NewReader(lines)
+
+	// The CSV reader reads from the buffer rather than wrapping it in another
+	csvReader := csv.NewReader(lines.buffer)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+	return csvReader, lines
+}
+
+// Read reads from the counter's reader and counts the line breaks read.
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if n > 0 {
+		c.breaks += bytes.Count(p[:n], []byte{'\n'})
+		c.last = p[n-1]
+	}
+	return n, err
+}
+
+// rowLine returns the line that the row the CSV reader just read starts on.
+//
+// The CSV reader stops reading at the end of each row, so the row ends on
+// the last line break that was read and not left in the buffer, or at the end
+// of the input if it doesn't end with a line break. Rows span more than one
+// line when quoted fields contain line breaks.
+func (c *lineCounter) rowLine(record []string) int {
+	buffered, _ := c.buffer.Peek(c.buffer.Buffered())
+
+	end := c.breaks - bytes.Count(buffered, []byte{'\n'})
+	if len(buffered) == 0 && c.last != '\n' {
+		end++
+	}
+	return end - lineBreaks(record)
+}
+
+// lineBreaks returns the number of line breaks in the fields of record.
+func lineBreaks(record []string) int {
+	count := 0
+	for _, field := range record {
+		count += strings.Count(field, "\n")
+	}
+	return count
+}
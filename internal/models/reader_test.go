@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRecordReaderFilter(t *testing.T) {
+	data := "date,location,new_cases,new_deaths,total_cases,total_deaths\n" +
+		"2020-03-01,Italy,1,0,1,0\n" +
+		"2020-03-01,United States,2,0,2,0\n" +
+		"2020-03-02,italy,2,0,3,0\n" +
+		"2020-03-02,USA,3,0,5,0\n" +
+		"2020-03-03,Italy,x,0,4,0\n" +
+		"2020-03-04,Italy,1,0,5,0\n"
+
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		filter *RecordFilter
+		want   []string
+	}{
+		{"none", nil, nil},
+		{"location", &RecordFilter{Location: "ITALY", To: day(2)}, []string{"Italy 1", "italy 3"}},
+		{"aliases", &RecordFilter{Location: "United States", Aliases: []string{"USA"}}, []string{"United States 2", "USA 5"}},
+		{"dates", &RecordFilter{Location: "Italy", From: day(4)}, []string{"Italy 5"}},
+		{"dates of every location", &RecordFilter{From: day(2), To: day(2)}, []string{"italy 3", "USA 5"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, err := NewRecordReader(strings.NewReader(data), "data.csv", nil, test.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for reader.Next() {
+				record := reader.Record()
+				got = append(got, fmt.Sprintf("%s %d", record.Location, record.TotalCases))
+			}
+
+			// Unselected rows aren't parsed, so only reading every row fails
+			if test.filter == nil {
+				if err = reader.Err(); err == nil {
+					t.Errorf("Err() = nil, want the malformed row's diagnostic")
+				}
+				return
+			}
+
+			if err = reader.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("records = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecordReaderLenient(t *testing.T) {
+	data := "date,location,new_cases,new_deaths,total_cases,total_deaths\n" +
+		"2020-03-01,Italy,1,0,1,0\n" +
+		"2020-03-02,It\"aly,2,0,3,0\n" +
+		"2020-03-03,Italy,1,0\n" +
+		"2020-03-04,Italy,x,0,4,0\n" +
+		"03/05/2020,Italy,1,0,5,0\n" +
+		"2020-03-06,,1,0,6,0\n" +
+		"2020-03-07,Italy,1,0,7,0\n"
+
+	want := []Diagnostic{
+		{File: "data.csv", Line: 3, Message: `bare " in non-quoted-field`},
+		{File: "data.csv", Line: 4, Message: "expected 6 columns but found 4"},
+		{File: "data.csv", Line: 5, Column: "new_cases", Value: "x", Message: "expected a number"},
+		{File: "data.csv", Line: 6, Column: "date", Value: "03/05/2020", Message: "expected a date formatted as YYYY-MM-DD"},
+		{File: "data.csv", Line: 7, Column: "location", Message: "empty location"},
+	}
+
+	reader, err := NewRecordReader(strings.NewReader(data), "data.csv", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for reader.Next() {
+	}
+	if !reflect.DeepEqual(reader.Err(), want[0]) {
+		t.Errorf("strict error = %#v, want %#v", reader.Err(), want[0])
+	}
+
+	reader, err = NewRecordReader(strings.NewReader(data), "data.csv", &ParseOptions{Lenient: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cases []int
+	for reader.Next() {
+		cases = append(cases, reader.Record().TotalCases)
+	}
+
+	if err = reader.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cases, []int{1, 7}) {
+		t.Errorf("records = %v, want the rows that could be parsed", cases)
+	}
+
+	if !reflect.DeepEqual(reader.Diagnostics(), want) {
+		t.Errorf("diagnostics = %v, want %v", reader.Diagnostics(), want)
+	}
+}
+
+func TestRecordReaderReadError(t *testing.T) {
+	data := io.MultiReader(
+		strings.NewReader("date,location,new_cases,new_deaths,total_cases,total_deaths\n2020-03-01,Italy,1,0,1,0\n"),
+		failingReader{syscall.EIO},
+	)
+
+	reader, err := NewRecordReader(data, "data.csv", &ParseOptions{Lenient: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := 0
+	for reader.Next() {
+		records++
+	}
+
+	if records != 1 {
+		t.Errorf("read %d records, want 1", records)
+	}
+	if err = reader.Err(); err == nil || !strings.Contains(err.Error(), syscall.EIO.Error()) {
+		t.Errorf("Err() = %v, want the read error", err)
+	}
+	if len(reader.Diagnostics()) > 0 {
+		t.Errorf("diagnostics = %v, want none", reader.Diagnostics())
+	}
+}
+
+// failingReader types fail every read with an error.
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AlignMode types describe the common date range that series are aligned
+// to.
+type AlignMode int
+
+// The alignment modes.
+const (
+	// AlignUnion aligns series to the range from the earliest first date to
+	// the latest last date of any series.
+	AlignUnion AlignMode = iota
+
+	// AlignIntersect aligns series to the range from the latest first date
+	// to the earliest last date of any series.
+	AlignIntersect
+)
+
+// The names of the alignment modes indexed by mode.
+var alignModeNames = []string{
+	AlignUnion:     "union",
+	AlignIntersect: "intersect",
+}
+
+// The length of a day in a daily calendar.
+const day = 24 * time.Hour
+
+// Series types contain the values of a metric indexed by date. Dates are in
+// ascending order, and dates whose value wasn't reported are gaps.
+type Series struct {
+
+	// The name of the series' location.
+	Name string
+
+	// The dates of the values.
+	Dates []time.Time
+
+	// The value of each date. Gaps are zero until they are filled.
+	Values []float64
+
+	// Whether or not each date's value was reported, rather than a gap.
+	Reported []bool
+}
+
+// MARK: Initializers
+
+// NewSeries creates and returns the series of the records' values of the
+// given metric. Dates of records that didn't report the metric are gaps.
+func NewSeries(name string, records []*COVRecord, metric Metric) *Series {
+	s := &Series{
+		Name:     name,
+		Dates:    make([]time.Time, len(records)),
+		Values:   make([]float64, len(records)),
+		Reported: make([]bool, len(records)),
+	}
+
+	for i, r := range records {
+		value, ok := r.Lookup(metric)
+		s.Dates[i] = r.Date
+		s.Values[i] = float64(value)
+		s.Reported[i] = ok
+	}
+
+	return s
+}
+
+// ParseAlignMode returns the alignment mode with the given case-insensitive
+// name.
+func ParseAlignMode(name string) (AlignMode, error) {
+	for i, modeName := range alignModeNames {
+		if strings.ToLower(modeName) == strings.ToLower(name) {
+			return AlignMode(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown alignment %q, expected one of %s", name, strings.Join(alignModeNames, ", "))
+}
+
+// MARK: Exported methods
+
+// Len returns the number of the series' dates.
+func (s Series) Len() int {
+	return len(s.Dates)
+}
+
+// Start returns the series' first date, or the zero time if it is empty.
+func (s Series) Start() time.Time {
+	if len(s.Dates) == 0 {
+		return time.Time{}
+	}
+	return s.Dates[0]
+}
+
+// End returns the series' last date, or the zero time if it is empty.
+func (s Series) End() time.Time {
+	if len(s.Dates) == 0 {
+		return time.Time{}
+	}
+	return s.Dates[len(s.Dates)-1]
+}
+
+// Index returns the index of the date in the series, or -1 if the series
+// doesn't have it.
+func (s Series) Index(date time.Time) int {
+	date = calendarDay(date)
+	i := sort.Search(len(s.Dates), func(i int) bool {
+		return !calendarDay(s.Dates[i]).Before(date)
+	})
+
+	if i < len(s.Dates) && calendarDay(s.Dates[i]).Equal(date) {
+		return i
+	}
+	return -1
+}
+
+// Lookup returns the value of the date and whether or not the series has a
+// reported or filled value on it.
+func (s Series) Lookup(date time.Time) (float64, bool) {
+	if i := s.Index(date); i >= 0 {
+		return s.Values[i], true
+	}
+	return 0, false
+}
+
+// Gaps returns the number of the series' dates whose value wasn't reported.
+func (s Series) Gaps() int {
+	gaps := 0
+	for _, reported := range s.Reported {
+		if !reported {
+			gaps++
+		}
+	}
+	return gaps
+}
+
+// Reindex returns a copy of the series on a continuous daily calendar from
+// the first to the last given date. Dates that aren't in the series are gaps,
+// and the series' dates outside of the range are dropped.
+func (s Series) Reindex(from time.Time, to time.Time) *Series {
+	from, to = calendarDay(from), calendarDay(to)
+
+	reindexed := &Series{Name: s.Name}
+	if to.Before(from) {
+		return reindexed
+	}
+
+	n := int(to.Sub(from)/day) + 1
+	reindexed.Dates = make([]time.Time, n)
+	reindexed.Values = make([]float64, n)
+	reindexed.Reported = make([]bool, n)
+
+	for i := range reindexed.Dates {
+		reindexed.Dates[i] = from.Add(time.Duration(i) * day)
+	}
+
+	for i, date := range s.Dates {
+		date = calendarDay(date)
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+
+		j := int(date.Sub(from) / day)
+		reindexed.Values[j] = s.Values[i]
+		reindexed.Reported[j] = s.Reported[i]
+	}
+
+	return reindexed
+}
+
+// Daily returns a copy of the series on a continuous daily calendar from its
+// first to its last date. The copy of an empty series is empty.
+func (s Series) Daily() *Series {
+	if s.Len() == 0 {
+		return &Series{Name: s.Name}
+	}
+	return s.Reindex(s.Start(), s.End())
+}
+
+// Fill returns a copy of the series with its gaps filled according to policy.
+// The skip policy drops the gaps' dates. Filled dates remain unreported.
+func (s Series) Fill(policy MissingPolicy) *Series {
+	filled := fillSignal(append([]float64(nil), s.Values...), s.Reported, policy)
+
+	result := &Series{Name: s.Name}
+	for i, date := range s.Dates {
+		if policy == MissingSkip && !s.Reported[i] {
+			continue
+		}
+
+		result.Dates = append(result.Dates, date)
+		result.Values = append(result.Values, filled[i])
+		result.Reported = append(result.Reported, s.Reported[i])
+	}
+
+	return result
+}
+
+// MARK: Exported functions
+
+// AlignSeries reindexes the series on to a common continuous daily calendar.
+// The calendar covers the union or the intersection of the series' date
+// ranges according to mode. Empty series are ignored when computing the
+// range.
+func AlignSeries(series []*Series, mode AlignMode) []*Series {
+	var from, to time.Time
+	for _, s := range series {
+		if s.Len() == 0 {
+			continue
+		}
+
+		start, end := calendarDay(s.Start()), calendarDay(s.End())
+		switch {
+		case from.IsZero():
+			from, to = start, end
+		case mode == AlignIntersect:
+			if start.After(from) {
+				from = start
+			}
+			if end.Before(to) {
+				to = end
+			}
+		default:
+			if start.Before(from) {
+				from = start
+			}
+			if end.After(to) {
+				to = end
+			}
+		}
+	}
+
+	aligned := make([]*Series, len(series))
+	for i, s := range series {
+		if from.IsZero() {
+			aligned[i] = &Series{Name: s.Name}
+		} else {
+			aligned[i] = s.Reindex(from, to)
+		}
+	}
+	return aligned
+}
+
+// AlignModeNames returns the names of every alignment mode.
+func AlignModeNames() []string {
+	return append([]string(nil), alignModeNames...)
+}
+
+// MARK: String interface methods
+
+func (m AlignMode) String() string {
+	return alignModeNames[m]
+}
+
+// MARK: Unexported functions
+
+// calendarDay returns the start of the date's day in UTC.
+func calendarDay(date time.Time) time.Time {
+	year, month, d := date.UTC().Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeriesReindex(t *testing.T) {
+	series := testSeries("Italy", 1, 2, 4)
+
+	tests := []struct {
+		name     string
+		reindex  func() *Series
+		start    int
+		values   []float64
+		reported []bool
+	}{
+		{"daily", series.Daily, 1, []float64{10, 20, 0, 40}, []bool{true, true, false, true}},
+		{"wider", func() *Series { return series.Reindex(marchDay(0), marchDay(5)) }, 0, []float64{0, 10, 20, 0, 40, 0}, []bool{false, true, true, false, true, false}},
+		{"narrower", func() *Series { return series.Reindex(marchDay(2), marchDay(3)) }, 2, []float64{20, 0}, []bool{true, false}},
+		{"time of day", func() *Series { return series.Reindex(marchDay(2).Add(18*time.Hour), marchDay(2).Add(time.Hour)) }, 2, []float64{20}, []bool{true}},
+		{"reversed", func() *Series { return series.Reindex(marchDay(4), marchDay(1)) }, 0, nil, nil},
+		{"empty", testSeries("Italy").Daily, 0, nil, nil},
+		{"empty reindexed", func() *Series { return testSeries("Italy").Reindex(marchDay(1), marchDay(2)) }, 1, []float64{0, 0}, []bool{false, false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.reindex()
+			assertSeries(t, got, "Italy", test.start, test.values, test.reported)
+		})
+	}
+}
+
+func TestSeriesFill(t *testing.T) {
+	series := testSeries("Italy", 2, 5).Reindex(marchDay(1), marchDay(6))
+	series.Values[1], series.Values[4] = 10, 40
+	reported := []bool{false, true, false, false, true, false}
+
+	tests := []struct {
+		policy   MissingPolicy
+		values   []float64
+		reported []bool
+	}{
+		{MissingZero, []float64{0, 10, 0, 0, 40, 0}, reported},
+		{MissingForwardFill, []float64{0, 10, 10, 10, 40, 40}, reported},
+		{MissingInterpolate, []float64{10, 10, 20, 30, 40, 40}, reported},
+		{MissingSkip, []float64{10, 40}, []bool{true, true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			filled := series.Fill(test.policy)
+
+			var dates []time.Time
+			for i, r := range reported {
+				if r || test.policy != MissingSkip {
+					dates = append(dates, series.Dates[i])
+				}
+			}
+
+			if !reflect.DeepEqual(filled.Dates, dates) {
+				t.Errorf("dates = %v, want %v", filled.Dates, dates)
+			}
+			if !reflect.DeepEqual(filled.Values, test.values) || !reflect.DeepEqual(filled.Reported, test.reported) {
+				t.Errorf("values = %v reported %v, want %v reported %v", filled.Values, filled.Reported, test.values, test.reported)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(series.Values, []float64{0, 10, 0, 0, 40, 0}) || series.Gaps() != 4 {
+		t.Errorf("Fill changed the series")
+	}
+
+	for _, policy := range []MissingPolicy{MissingZero, MissingSkip, MissingForwardFill, MissingInterpolate} {
+		if filled := testSeries("Italy").Fill(policy); filled.Len() != 0 {
+			t.Errorf("Fill(%v) of an empty series has %d dates", policy, filled.Len())
+		}
+
+		gaps := testSeries("Italy").Reindex(marchDay(1), marchDay(3))
+		want := []float64{0, 0, 0}
+		if policy == MissingSkip {
+			want = nil
+		}
+		if filled := gaps.Fill(policy); !reflect.DeepEqual(filled.Values, want) {
+			t.Errorf("Fill(%v) of a series of gaps = %v, want %v", policy, filled.Values, want)
+		}
+	}
+}
+
+func TestAlignSeries(t *testing.T) {
+	type want struct {
+		start    int
+		values   []float64
+		reported []bool
+	}
+
+	tests := []struct {
+		name   string
+		series []*Series
+		mode   AlignMode
+		want   []want
+	}{
+		{
+			name:   "union",
+			series: []*Series{testSeries("a", 1, 2, 3), testSeries("b", 2, 4, 5), testSeries("c")},
+			mode:   AlignUnion,
+			want: []want{
+				{1, []float64{10, 20, 30, 0, 0}, []bool{true, true, true, false, false}},
+				{1, []float64{0, 20, 0, 40, 50}, []bool{false, true, false, true, true}},
+				{1, []float64{0, 0, 0, 0, 0}, []bool{false, false, false, false, false}},
+			},
+		},
+		{
+			name:   "intersect",
+			series: []*Series{testSeries("a", 1, 2, 3), testSeries("b", 2, 4, 5), testSeries("c")},
+			mode:   AlignIntersect,
+			want: []want{
+				{2, []float64{20, 30}, []bool{true, true}},
+				{2, []float64{20, 0}, []bool{true, false}},
+				{2, []float64{0, 0}, []bool{false, false}},
+			},
+		},
+		{
+			name:   "non-overlapping intersect",
+			series: []*Series{testSeries("a", 1, 2), testSeries("b", 4, 5)},
+			mode:   AlignIntersect,
+			want:   []want{{0, nil, nil}, {0, nil, nil}},
+		},
+		{
+			name:   "non-overlapping union",
+			series: []*Series{testSeries("a", 1), testSeries("b", 3)},
+			mode:   AlignUnion,
+			want: []want{
+				{1, []float64{10, 0, 0}, []bool{true, false, false}},
+				{1, []float64{0, 0, 30}, []bool{false, false, true}},
+			},
+		},
+		{
+			name:   "empty",
+			series: []*Series{testSeries("a"), testSeries("b")},
+			mode:   AlignUnion,
+			want:   []want{{0, nil, nil}, {0, nil, nil}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aligned := AlignSeries(test.series, test.mode)
+			if len(aligned) != len(test.want) {
+				t.Fatalf("aligned %d series, want %d", len(aligned), len(test.want))
+			}
+
+			for i, w := range test.want {
+				assertSeries(t, aligned[i], test.series[i].Name, w.start, w.values, w.reported)
+			}
+		})
+	}
+}
+
+func TestSeriesIndex(t *testing.T) {
+	series := testSeries("Italy", 1, 2, 4)
+
+	tests := []struct {
+		date  time.Time
+		index int
+		value float64
+	}{
+		{marchDay(1), 0, 10},
+		{marchDay(2).Add(23 * time.Hour), 1, 20},
+		{time.Date(2020, 3, 4, 0, 30, 0, 0, time.FixedZone("CET", 3600)), -1, 0},
+		{time.Date(2020, 3, 4, 1, 0, 0, 0, time.FixedZone("EST", -5*3600)), 2, 40},
+		{marchDay(3), -1, 0},
+		{marchDay(0), -1, 0},
+		{marchDay(5), -1, 0},
+	}
+
+	for _, test := range tests {
+		if index := series.Index(test.date); index != test.index {
+			t.Errorf("Index(%v) = %d, want %d", test.date, index, test.index)
+		}
+
+		value, ok := series.Lookup(test.date)
+		if ok != (test.index >= 0) || value != test.value {
+			t.Errorf("Lookup(%v) = %v, %v, want %v, %v", test.date, value, ok, test.value, test.index >= 0)
+		}
+	}
+
+	if index := testSeries("Italy").Index(marchDay(1)); index != -1 {
+		t.Errorf("Index of an empty series = %d, want -1", index)
+	}
+}
+
+// testSeries returns a series of the given days of March 2020 whose values
+// are ten times the day.
+func testSeries(name string, days ...int) *Series {
+	s := &Series{Name: name}
+	for _, d := range days {
+		s.Dates = append(s.Dates, marchDay(d))
+		s.Values = append(s.Values, float64(10*d))
+		s.Reported = append(s.Reported, true)
+	}
+	return s
+}
+
+// marchDay returns the given day of March 2020 in UTC.
+func marchDay(d int) time.Time {
+	return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+// assertSeries checks that the series is named name and has a daily date
+// from the given day of March 2020 for each of the values.
+func assertSeries(t *testing.T, s *Series, name string, start int, values []float64, reported []bool) {
+	t.Helper()
+
+	if s.Name != name {
+		t.Errorf("name = %q, want %q", s.Name, name)
+	}
+
+	var dates []time.Time
+	for i := range values {
+		dates = append(dates, marchDay(start+i))
+	}
+
+	if !reflect.DeepEqual(s.Dates, dates) {
+		t.Errorf("dates = %v, want %v", s.Dates, dates)
+	}
+	if !reflect.DeepEqual(s.Values, values) || !reflect.DeepEqual(s.Reported, reported) {
+		t.Errorf("values = %v reported %v, want %v reported %v", s.Values, s.Reported, values, reported)
+	}
+}
@@ -0,0 +1,310 @@
+package models
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sort"
+	"time"
+)
+
+// Table types are the encoding that worlds are stored in, such as in the
+// binary cache. Dates are kept once in a shared, sorted index, each location
+// stores its metrics in one array per metric and location names are
+// interned, so an encoded table takes a fraction of the space of an encoded
+// world.
+//
+// Tables only encode and decode worlds. Use NewTable to create a table from a
+// world and Table.World to convert a decoded table back.
+type Table struct {
+	dates       []time.Time
+	locations   []*tableLocation
+	world       *tableLocation
+	diagnostics []Diagnostic
+	synthesized bool
+}
+
+// tableLocation types contain the columns of a location in a table.
+type tableLocation struct {
+	name  string
+	table *Table
+
+	// The positions of the location's dates in the table's date index.
+	dates []int32
+
+	// The values of each metric indexed by metric. Columns of metrics that
+	// are missing from every row are nil.
+	values [][]int64
+
+	// The missing metrics of each row.
+	missing []MetricSet
+
+	// The location's regions.
+	children []*tableLocation
+}
+
+// tableData types are the exported form of a table used for encoding.
+type tableData struct {
+	Dates       []int64
+	Names       []string
+	Locations   []tableLocationData
+	World       tableLocationData
+	Diagnostics []Diagnostic
+	Synthesized bool
+}
+
+// tableLocationData types are the exported form of a table location used for
+// encoding.
+type tableLocationData struct {
+	Name     int
+	Dates    []int32
+	Values   [][]int64
+	Missing  []MetricSet
+	Children []tableLocationData
+}
+
+// MARK: Initializers
+
+// NewTable creates and returns a new table containing the world's locations
+// and records.
+func NewTable(world *World) *Table {
+	table := &Table{
+		diagnostics: world.Diagnostics,
+		synthesized: world.Synthesized,
+	}
+
+	// Build the shared date index
+	positions := make(map[time.Time]int32)
+	addDates := func(records []*COVRecord) {
+		for _, r := range records {
+			if _, ok := positions[r.Date]; !ok {
+				positions[r.Date] = 0
+				table.dates = append(table.dates, r.Date)
+			}
+		}
+	}
+
+	for _, location := range world.Locations {
+		location.Walk(func(l *Location) {
+			addDates(l.Records)
+		})
+	}
+	addDates(world.Records)
+
+	sort.Slice(table.dates, func(i, j int) bool {
+		return table.dates[i].Before(table.dates[j])
+	})
+	for i, date := range table.dates {
+		positions[date] = int32(i)
+	}
+
+	// Store the records in columns
+	names := make(map[string]string)
+	var newLocation func(location *Location) *tableLocation
+	newLocation = func(location *Location) *tableLocation {
+		l := table.newLocation(internName(names, location.Name), location.Records, positions)
+		for _, child := range location.Children {
+			l.children = append(l.children, newLocation(child))
+		}
+		return l
+	}
+
+	for _, location := range world.Locations {
+		table.locations = append(table.locations, newLocation(location))
+	}
+	table.world = table.newLocation(internName(names, "World"), world.Records, positions)
+
+	return table
+}
+
+// MARK: Exported methods
+
+// World creates and returns a world containing the table's locations and
+// records. The records of each location are allocated together and share
+// the location's name.
+func (t Table) World() *World {
+	var locations []*Location
+	for _, l := range t.locations {
+		locations = append(locations, l.location())
+	}
+
+	return &World{
+		Locations:   locations,
+		Records:     t.world.records(),
+		Diagnostics: t.diagnostics,
+		Synthesized: t.synthesized,
+		index:       newLocationIndex(locations),
+	}
+}
+
+// MARK: GobEncoder interface methods
+
+// GobEncode encodes the table.
+func (t Table) GobEncode() ([]byte, error) {
+	data := tableData{
+		Dates:       make([]int64, len(t.dates)),
+		Diagnostics: t.diagnostics,
+		Synthesized: t.synthesized,
+	}
+
+	for i, date := range t.dates {
+		data.Dates[i] = date.Unix()
+	}
+
+	names := make(map[string]int)
+	var encodeLocation func(l *tableLocation) tableLocationData
+	encodeLocation = func(l *tableLocation) tableLocationData {
+		name, ok := names[l.name]
+		if !ok {
+			name = len(data.Names)
+			names[l.name] = name
+			data.Names = append(data.Names, l.name)
+		}
+
+		encoded := tableLocationData{
+			Name:    name,
+			Dates:   l.dates,
+			Values:  l.values,
+			Missing: l.missing,
+		}
+
+		for _, child := range l.children {
+			encoded.Children = append(encoded.Children, encodeLocation(child))
+		}
+		return encoded
+	}
+
+	for _, l := range t.locations {
+		data.Locations = append(data.Locations, encodeLocation(l))
+	}
+	data.World = encodeLocation(t.world)
+
+	buffer := &bytes.Buffer{}
+	err := gob.NewEncoder(buffer).Encode(data)
+	return buffer.Bytes(), err
+}
+
+// MARK: GobDecoder interface methods
+
+// GobDecode decodes the table.
+func (t *Table) GobDecode(encoded []byte) error {
+	data := tableData{}
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&data); err != nil {
+		return err
+	}
+
+	t.dates = make([]time.Time, len(data.Dates))
+	for i, date := range data.Dates {
+		t.dates[i] = time.Unix(date, 0).UTC()
+	}
+
+	var decodeLocation func(l tableLocationData) *tableLocation
+	decodeLocation = func(l tableLocationData) *tableLocation {
+		values := make([][]int64, len(metrics))
+		copy(values, l.Values)
+
+		location := &tableLocation{
+			name:    data.Names[l.Name],
+			table:   t,
+			dates:   l.Dates,
+			values:  values,
+			missing: l.Missing,
+		}
+
+		for _, child := range l.Children {
+			location.children = append(location.children, decodeLocation(child))
+		}
+		return location
+	}
+
+	t.locations = make([]*tableLocation, len(data.Locations))
+	for i, l := range data.Locations {
+		t.locations[i] = decodeLocation(l)
+	}
+	t.world = decodeLocation(data.World)
+	t.diagnostics = data.Diagnostics
+	t.synthesized = data.Synthesized
+
+	return nil
+}
+
+// MARK: Unexported methods
+
+// newLocation creates and returns a new table location containing the given
+// records, whose dates are at the given positions of the table's date index.
+func (t *Table) newLocation(name string, records []*COVRecord, positions map[time.Time]int32) *tableLocation {
+	location := &tableLocation{
+		name:    name,
+		table:   t,
+		dates:   make([]int32, len(records)),
+		values:  make([][]int64, len(metrics)),
+		missing: make([]MetricSet, len(records)),
+	}
+
+	for i, r := range records {
+		location.dates[i] = positions[r.Date]
+		location.missing[i] = r.Missing
+
+		for m := range metrics {
+			metric := Metric(m)
+			if r.Missing.Has(metric) {
+				continue
+			}
+
+			// Only allocate the columns of reported metrics
+			if location.values[metric] == nil {
+				location.values[metric] = make([]int64, len(records))
+			}
+			location.values[metric][i] = int64(r.Value(metric))
+		}
+	}
+
+	return location
+}
+
+// records creates and returns the location's records.
+func (l tableLocation) records() []*COVRecord {
+	// Allocate the records together rather than one at a time
+	block := make([]COVRecord, len(l.dates))
+	records := make([]*COVRecord, len(l.dates))
+
+	for i := range block {
+		l.fill(&block[i], i)
+		records[i] = &block[i]
+	}
+
+	return records
+}
+
+// location creates and returns a location containing the location's records
+// and regions.
+func (l tableLocation) location() *Location {
+	location := NewLocation(l.name, l.records())
+	for _, child := range l.children {
+		location.AddChild(child.location())
+	}
+	return location
+}
+
+// fill sets the record's fields to the values of the row at index i.
+func (l tableLocation) fill(record *COVRecord, i int) {
+	record.Date = l.table.dates[l.dates[i]]
+	record.Location = l.name
+	record.Missing = l.missing[i]
+
+	for metric, column := range l.values {
+		if column != nil && !l.missing[i].Has(Metric(metric)) {
+			record.SetValue(Metric(metric), int(column[i]))
+		}
+	}
+}
+
+// MARK: Unexported functions
+
+// internName returns the interned copy of name.
+func internName(names map[string]string, name string) string {
+	if interned, ok := names[name]; ok {
+		return interned
+	}
+	names[name] = name
+	return name
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func TestTableRoundTrip(t *testing.T) {
+	path := writeDataset(t, `date,location,new_cases,new_deaths,total_cases,total_deaths,people_vaccinated
+2020-03-01,Italy,1,0,1,0,
+2020-03-02,Italy,2,,3,,10
+2020-03-03,Italy,x,0,3,0,
+2020-03-01,Spain,4,1,4,1,
+2020-03-03,Spain,1,0,5,1,
+`)
+
+	parsed, err := NewWorldFromPathWithOptions(path, &ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	regions := testRegionWorld(map[string]int{"Kings": 10, "Queens": 5, "Travis": 3})
+	regions.Diagnostics = []Diagnostic{{File: "us.csv", Line: 2, Message: "expected 5 columns but found 3"}}
+
+	tests := []struct {
+		name  string
+		world *World
+	}{
+		{"parsed", parsed},
+		{"regions", regions},
+		{"empty", NewWorldFromLocations(nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if world := NewTable(test.world).World(); !reflect.DeepEqual(world, test.world) {
+				t.Errorf("NewTable(world).World() differs from the world")
+			}
+
+			buffer := &bytes.Buffer{}
+			if err := gob.NewEncoder(buffer).Encode(NewTable(test.world)); err != nil {
+				t.Fatal(err)
+			}
+
+			decoded := &Table{}
+			if err := gob.NewDecoder(buffer).Decode(decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			if world := decoded.World(); !reflect.DeepEqual(world, test.world) {
+				t.Errorf("decoded world differs from the world")
+			}
+		})
+	}
+
+	if len(parsed.Diagnostics) != 1 || !parsed.Synthesized {
+		t.Errorf("parsed world = %d diagnostics and synthesized %v, want 1 and true", len(parsed.Diagnostics), parsed.Synthesized)
+	}
+	if r := parsed.Location("Italy").Records[1]; !r.IsMissing(MetricNewDeaths) || r.IsMissing(MetricPeopleVaccinated) {
+		t.Errorf("parsed world doesn't have missing and extended values")
+	}
+}
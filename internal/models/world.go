@@ -1,11 +1,7 @@
 package models
 
 import (
-	"bufio"
-	"encoding/csv"
 	"fmt"
-	"io"
-	"os"
 	"sort"
 	"strings"
 )
@@ -18,6 +14,20 @@ type World struct {
 
 	// The world's records.
 	Records []*COVRecord
+
+	// The problems with rows that were skipped while parsing leniently.
+	Diagnostics []Diagnostic
+
+	// Whether or not the world's records were computed from its locations
+	// because the dataset didn't contain any.
+	Synthesized bool
+
+	// The metadata of the world's records' location, or nil if it isn't
+	// known.
+	Info *LocationInfo
+
+	// The locations keyed by their normalized names and ISO codes.
+	index map[string]*Location
 }
 
 // MARK: Initializers
@@ -27,93 +37,245 @@ func NewWorld(locations []*Location, records []*COVRecord) *World {
 	return &World{
 		Locations: locations,
 		Records:   records,
+		index:     newLocationIndex(locations),
+	}
+}
+
+// NewWorldFromLocations creates and returns a new world whose records are
+// the sum of the given locations. Aggregate locations, such as continents,
+// aren't counted.
+func NewWorldFromLocations(locations []*Location) *World {
+	return &World{
+		Locations:   locations,
+		Records:     AggregateRecords("World", memberLocations(locations)),
+		Synthesized: true,
+		index:       newLocationIndex(locations),
 	}
 }
 
 // NewWorldFromPath creats and returns a new world object from the given
 // CSV path.
 func NewWorldFromPath(path string) (*World, error) {
+	return NewWorldFromPathWithOptions(path, nil)
+}
+
+// NewWorldFromPathWithOptions creates and returns a new world object from
+// the given CSV path using the given parse options. Columns are mapped by the
+// names in the CSV's header row.
+//
+// Rows that can't be parsed fail the parse with a Diagnostic error that
+// contains the file, line, column and raw value of the problem. When parsing
+// leniently those rows are skipped and their diagnostics are collected in the
+// world's Diagnostics instead.
+//
+// Large datasets are parsed in parallel by the number of workers in options.
+// The world is the same for any number of workers.
+func NewWorldFromPathWithOptions(path string, options *ParseOptions) (*World, error) {
 	// Read records from the csv
-	file, err := os.Open(path)
+	covRecords, _, diagnostics, err := readRecords(path, options, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	defer file.Close()
+	return newWorldFromRecords(covRecords, diagnostics), nil
+}
 
-	csvReader := csv.NewReader(bufio.NewReader(file))
-	csvReader.FieldsPerRecord = 6
+// MARK: Exported methods
 
-	var world *Location
-	var locations []*Location
-	var records []*COVRecord
-	location := ""
+// TotalCases returns the total cases at the location.
+func (w World) TotalCases() int {
+	if len(w.Records) > 0 {
+		return w.Records[len(w.Records)-1].TotalCases
+	}
+	return 0
+}
 
-	for {
-		record, readError := csvReader.Read()
-		if readError != nil {
-			if readError == io.EOF {
-				break
-			}
+// TotalDeaths returns the total cases at the location.
+func (w World) TotalDeaths() int {
+	if len(w.Records) > 0 {
+		return w.Records[len(w.Records)-1].TotalCases
+	}
+	return 0
+}
+
+// CheckAggregate compares the world's records with the sum of its locations
+// and returns every value that differs, with the world's value as the old
+// value and the sum as the new value. Aggregate locations, such as continents
+// and income groups, are left out of the sum because their members are
+// already in it.
+func (w World) CheckAggregate() []ValueChange {
+	computed := NewLocation("World", AggregateRecords("World", memberLocations(w.Locations)))
+	provided := NewLocation("World", w.Records)
+
+	diff := CompareWorlds(
+		&World{Locations: []*Location{provided}},
+		&World{Locations: []*Location{computed}},
+	)
+	return diff.Changes
+}
+
+// Value returns the latest value of the given metric.
+func (w World) Value(metric Metric) int {
+	if len(w.Records) > 0 {
+		return w.Records[len(w.Records)-1].Value(metric)
+	}
+	return 0
+}
+
+// Signal returns the world's records' values of the given metric as a float
+// slice with missing values treated according to policy.
+func (w World) Signal(metric Metric, policy MissingPolicy) []float64 {
+	return recordsSignal(w.Records, metric, policy)
+}
+
+// Series returns the world's series of the given metric.
+func (w World) Series(metric Metric) *Series {
+	name := "World"
+	if len(w.Records) > 0 {
+		name = w.Records[0].Location
+	}
+	return NewSeries(name, w.Records, metric)
+}
+
+// SignalForLocation returns the location's records' values of the given
+// metric as a float slice with missing values treated according to policy.
+func (w World) SignalForLocation(location string, metric Metric, policy MissingPolicy) []float64 {
+	if l := w.Location(location); l != nil {
+		return l.Signal(metric, policy)
+	}
+	return nil
+}
+
+// Location returns the location with the given case-insensitive name, ISO
+// code or alias, or nil if the world doesn't have one. Names take precedence
+// over codes and aliases.
+//
+// Regions are found by their paths, e.g. "United States/New York", where
+// each name is resolved among the regions of the location before it.
+func (w World) Location(name string) *Location {
+	if l := w.topLevelLocation(name); l != nil || !strings.Contains(name, LocationPathSeparator) {
+		return l
+	}
 
-			return nil, err
+	names := SplitLocationPath(name)
+	l := w.topLevelLocation(names[0])
+	for _, name := range names[1:] {
+		if l == nil {
+			return nil
 		}
+		l = l.Child(name)
+	}
+	return l
+}
 
-		covRecord, err := NewCOVRecord(record)
-		if err != nil {
-			if err.Error() == "header" {
-				continue
-			}
+// RollUp rolls up the regions of each of the world's locations in to the
+// locations that contain them.
+func (w *World) RollUp() {
+	for _, l := range w.Locations {
+		l.RollUp()
+	}
+}
+
+// LocationNames returns the names of the world's locations.
+func (w World) LocationNames() []string {
+	names := make([]string, len(w.Locations))
+	for i, l := range w.Locations {
+		names[i] = l.Name
+	}
+	return names
+}
 
-			return nil, err
+// Aggregate returns a location named after the group whose records are the
+// sum of the world's locations in the group, or nil if none of them are.
+// Members match locations by name or alias, or by the name or ISO codes of
+// their metadata, so datasets that name locations differently are grouped
+// alike.
+//
+// The aggregate's metadata only has a population, which is the sum of its
+// locations' populations if all of them are known.
+func (w World) Aggregate(group *LocationGroup) *Location {
+	members := make(map[string]bool)
+	for _, member := range group.Members {
+		members[normalizeLocationName(member)] = true
+	}
+
+	var locations []*Location
+	for _, l := range w.Locations {
+		// Copy the aliases so that they aren't appended to
+		names := append([]string(nil), LocationAliases(l.Name)...)
+		if l.Info != nil {
+			names = append(names, l.Info.Name, l.Info.ISO2, l.Info.ISO3)
 		}
 
-		if covRecord.Location != location && len(records) > 0 && len(location) > 0 {
-			if strings.ToLower(location) == "world" {
-				world = NewLocation(location, records)
-			} else {
-				locations = append(locations, NewLocation(location, records))
+		for _, name := range names {
+			if len(name) > 0 && members[normalizeLocationName(name)] {
+				locations = append(locations, l)
+				break
 			}
-
-			location = covRecord.Location
-			records = nil
-		} else if covRecord.Location != location {
-			location = covRecord.Location
 		}
+	}
 
-		records = append(records, covRecord)
+	if len(locations) == 0 {
+		return nil
 	}
 
-	if len(records) > 0 {
-		if strings.ToLower(location) == "world" {
-			world = NewLocation(location, records)
-		} else {
-			locations = append(locations, NewLocation(location, records))
+	info := &LocationInfo{Name: group.Name}
+	for _, l := range locations {
+		population := l.Population()
+		if population <= 0 {
+			info.Population = 0
+			break
 		}
+		info.Population += population
 	}
 
-	return &World{
-		Locations: locations,
-		Records:   world.Records,
-	}, nil
+	aggregate := NewLocation(group.Name, AggregateRecords(group.Name, locations))
+	aggregate.Info = info
+	return aggregate
 }
 
-// MARK: Exported methods
+// Population returns the world's population from its metadata, or the latest
+// population of its records if its metadata doesn't have one. Zero is
+// returned if the population isn't known.
+func (w World) Population() int {
+	if w.Info != nil && w.Info.Population > 0 {
+		return w.Info.Population
+	}
+	return latestPopulation(w.Records)
+}
 
-// TotalCases returns the total cases at the location.
-func (w World) TotalCases() int {
-	if len(w.Records) > 0 {
-		return w.Records[len(w.Records)-1].TotalCases
+// PerMillionSignal returns the world's records' values of the given metric
+// per million people as a float slice with missing values treated according
+// to policy, or nil if the world's population isn't known.
+func (w World) PerMillionSignal(metric Metric, policy MissingPolicy) []float64 {
+	return perMillionSignal(w.Signal(metric, policy), w.Population())
+}
+
+// PerMillionSignalForLocation returns the location's records' values of the
+// given metric per million people as a float slice with missing values
+// treated according to policy, or nil if the location's population isn't
+// known.
+func (w World) PerMillionSignalForLocation(location string, metric Metric, policy MissingPolicy) []float64 {
+	if l := w.Location(location); l != nil {
+		return l.PerMillionSignal(metric, policy)
 	}
-	return 0
+	return nil
 }
 
-// TotalDeaths returns the total cases at the location.
-func (w World) TotalDeaths() int {
+// JoinLocationInfo sets the metadata of the world and each of its locations
+// to their metadata in the table. Locations that aren't in the table have no
+// metadata. The locations are indexed by the ISO codes of their metadata.
+func (w *World) JoinLocationInfo(table *LocationTable) {
+	for _, l := range w.Locations {
+		l.Info, _ = table.Lookup(l.Name)
+	}
+	w.index = newLocationIndex(w.Locations)
+
+	name := "World"
 	if len(w.Records) > 0 {
-		return w.Records[len(w.Records)-1].TotalCases
+		name = w.Records[0].Location
 	}
-	return 0
+	w.Info, _ = table.Lookup(name)
 }
 
 // ListData lists the world data.
@@ -134,85 +296,170 @@ func (w World) ListLocationData() {
 	}
 }
 
-// Sort sorts the world data by the given descriptor and order.
+// Sort sorts the world data by the given descriptor and order. The
+// descriptor is either "name" or the name of a metric.
 func (w *World) Sort(descriptor string, order string) {
+	w.sort(descriptor, order, func(l *Location, metric Metric) float64 {
+		return float64(l.Value(metric))
+	})
+}
+
+// SortPerMillion sorts the world data like Sort, but compares the values of
+// metrics per million people. Locations whose population isn't known have a
+// value of zero.
+func (w *World) SortPerMillion(descriptor string, order string) {
+	w.sort(descriptor, order, func(l *Location, metric Metric) float64 {
+		value, _ := l.PerMillion(metric)
+		return value
+	})
+}
+
+// TotalCasesSignal returns the world's records' total cases
+// as a float slice with missing values treated according to policy.
+func (w World) TotalCasesSignal(policy MissingPolicy) []float64 {
+	return w.Signal(MetricTotalCases, policy)
+}
+
+// TotalDeathsSignal returns the world's records' total deaths
+// as a float slice with missing values treated according to policy.
+func (w World) TotalDeathsSignal(policy MissingPolicy) []float64 {
+	return w.Signal(MetricTotalDeaths, policy)
+}
+
+// TotalCasesSignalForLocation returns the location's records' total
+// cases as a float slice with missing values treated according to policy.
+func (w World) TotalCasesSignalForLocation(location string, policy MissingPolicy) []float64 {
+	return w.SignalForLocation(location, MetricTotalCases, policy)
+}
+
+// TotalDeathsSignalForLocation returns the location's records' total
+// deaths as a float slice with missing values treated according to policy.
+func (w World) TotalDeathsSignalForLocation(location string, policy MissingPolicy) []float64 {
+	return w.SignalForLocation(location, MetricTotalDeaths, policy)
+}
+
+// MARK: Unexported methods
+
+// topLevelLocation returns the top-level location with the given
+// case-insensitive name, ISO code or alias.
+func (w World) topLevelLocation(name string) *Location {
+	index := w.index
+	if index == nil {
+		index = newLocationIndex(w.Locations)
+	}
+
+	if l, ok := index[normalizeLocationName(name)]; ok {
+		return l
+	}
+
+	for _, alias := range LocationAliases(name) {
+		if l, ok := index[normalizeLocationName(alias)]; ok {
+			return l
+		}
+	}
+	return nil
+}
+
+// sort sorts the world data by the given descriptor and order, comparing the
+// locations' values of metrics with value.
+func (w *World) sort(descriptor string, order string, value func(l *Location, metric Metric) float64) {
+	metric, err := ParseMetric(descriptor)
+	byName := err != nil
+
 	sort.Slice(w.Locations, func(i, j int) bool {
 		iLocation := w.Locations[i]
 		jLocation := w.Locations[j]
 
-		switch strings.ToLower(descriptor) {
-		case "name":
-			if order == "desc" {
-				return strings.Compare(iLocation.Name, jLocation.Name) < 0
-			}
-			return strings.Compare(iLocation.Name, jLocation.Name) > 0
-		case "newcases":
-			if order == "desc" {
-				return iLocation.NewCases() < jLocation.NewCases()
-			}
-			return iLocation.NewCases() > jLocation.NewCases()
-		case "newdeaths":
-			if order == "desc" {
-				return iLocation.NewDeaths() < jLocation.NewDeaths()
-			}
-			return iLocation.NewDeaths() > jLocation.NewDeaths()
-		case "totalcases":
-			if order == "desc" {
-				return iLocation.TotalCases() < jLocation.TotalCases()
-			}
-			return iLocation.TotalCases() > jLocation.TotalCases()
-		case "totaldeaths":
-			if order == "desc" {
-				return iLocation.TotalDeaths() < jLocation.TotalDeaths()
-			}
-			return iLocation.TotalDeaths() > jLocation.TotalDeaths()
-		default:
+		if byName {
 			if order == "desc" {
 				return strings.Compare(iLocation.Name, jLocation.Name) < 0
 			}
 			return strings.Compare(iLocation.Name, jLocation.Name) > 0
 		}
+
+		if order == "desc" {
+			return value(iLocation, metric) < value(jLocation, metric)
+		}
+		return value(iLocation, metric) > value(jLocation, metric)
 	})
 }
 
-// TotalCasesSignal returns the world's records' total cases
-// as a float slice.
-func (w World) TotalCasesSignal() []float64 {
-	var signal []float64
-	for _, r := range w.Records {
-		signal = append(signal, float64(r.TotalCases))
+// MARK: Unexported functions
+
+// newLocationIndex creates and returns an index of the locations by their
+// normalized names, and by the names and ISO codes of their metadata. Names
+// take precedence over metadata.
+func newLocationIndex(locations []*Location) map[string]*Location {
+	index := make(map[string]*Location, len(locations))
+	for _, l := range locations {
+		index[normalizeLocationName(l.Name)] = l
 	}
-	return signal
-}
 
-// TotalDeathsSignal returns the world's records' total deaths
-// as a float slice.
-func (w World) TotalDeathsSignal() []float64 {
-	var signal []float64
-	for _, r := range w.Records {
-		signal = append(signal, float64(r.TotalDeaths))
+	for _, l := range locations {
+		if l.Info == nil {
+			continue
+		}
+
+		for _, key := range []string{l.Info.Name, l.Info.ISO2, l.Info.ISO3} {
+			key = normalizeLocationName(key)
+			if _, ok := index[key]; len(key) > 0 && !ok {
+				index[key] = l
+			}
+		}
 	}
-	return signal
+
+	return index
 }
 
-// TotalCasesSignalForLocation returns the location's records' total
-// cases as a float slice.
-func (w World) TotalCasesSignalForLocation(location string) []float64 {
-	for _, l := range w.Locations {
-		if strings.ToLower(location) == strings.ToLower(l.Name) {
-			return l.TotalCasesSignal()
+// newWorldFromRecords creates and returns a new world from the records of a
+// dataset in the order of the file. Consecutive records of a location form
+// the location, and records of a location named "world" form the world's
+// records. The world's records are computed from its locations, other than
+// aggregates such as continents, if there are none.
+func newWorldFromRecords(covRecords []*COVRecord, diagnostics []Diagnostic) *World {
+	var world *Location
+	var locations []*Location
+	var records []*COVRecord
+	location := ""
+
+	for _, covRecord := range covRecords {
+		if covRecord.Location != location && len(records) > 0 && len(location) > 0 {
+			if strings.ToLower(location) == "world" {
+				world = NewLocation(location, records)
+			} else {
+				locations = append(locations, NewLocation(location, records))
+			}
+
+			location = covRecord.Location
+			records = nil
+		} else if covRecord.Location != location {
+			location = covRecord.Location
 		}
+
+		// Share one copy of the location's name between its records
+		covRecord.Location = location
+		records = append(records, covRecord)
 	}
-	return nil
-}
 
-// TotalDeathsSignalForLocation returns the location's records' total
-// deaths as a float slice.
-func (w World) TotalDeathsSignalForLocation(location string) []float64 {
-	for _, l := range w.Locations {
-		if strings.ToLower(location) == strings.ToLower(l.Name) {
-			return l.TotalDeathsSignal()
+	if len(records) > 0 {
+		if strings.ToLower(location) == "world" {
+			world = NewLocation(location, records)
+		} else {
+			locations = append(locations, NewLocation(location, records))
 		}
 	}
-	return nil
+
+	// Sum the locations if the dataset doesn't have a world series
+	synthesized := world == nil
+	if synthesized {
+		world = NewLocation("World", AggregateRecords("World", memberLocations(locations)))
+	}
+
+	return &World{
+		Locations:   locations,
+		Records:     world.Records,
+		Diagnostics: diagnostics,
+		Synthesized: synthesized,
+		index:       newLocationIndex(locations),
+	}
 }
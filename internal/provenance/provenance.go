@@ -0,0 +1,177 @@
+// Package provenance contains the metadata that records where each dataset
+// file came from.
+package provenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/fetch"
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// The suffix of the metadata file stored next to each dataset file.
+const metadataSuffix = ".meta.json"
+
+// Metadata types describe where a dataset file came from. Metadata is stored
+// in a sidecar file next to the dataset file.
+type Metadata struct {
+
+	// The name of the dataset file.
+	File string `json:"file"`
+
+	// The URL the file was downloaded from, or the path it was imported
+	// from.
+	URL string `json:"url,omitempty"`
+
+	// The time the file's contents were last downloaded or imported.
+	FetchedAt time.Time `json:"fetchedAt"`
+
+	// The time the URL was last checked for changes.
+	CheckedAt time.Time `json:"checkedAt"`
+
+	// The HTTP validators of the download.
+	Validators fetch.Validators `json:"validators"`
+
+	// The hex encoded SHA-256 hash of the file's contents.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// The size of the file in bytes.
+	Size int64 `json:"size"`
+
+	// The ID of the file's snapshot.
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// The counts of the dataset parsed from the data source's files, or nil
+	// if it hasn't been parsed.
+	Counts *Counts `json:"counts,omitempty"`
+}
+
+// Counts types contain the size of a parsed dataset.
+type Counts struct {
+
+	// The number of location records.
+	Rows int `json:"rows"`
+
+	// The number of locations.
+	Locations int `json:"locations"`
+
+	// The number of distinct dates.
+	Dates int `json:"dates"`
+
+	// The first and last dates (YYYY-MM-DD) of the dataset.
+	FirstDate string `json:"firstDate,omitempty"`
+	LastDate  string `json:"lastDate,omitempty"`
+}
+
+// ChecksumError types are returned when a file's hash doesn't match the
+// checksum it is pinned to.
+type ChecksumError struct {
+
+	// The name of the file.
+	File string
+
+	// The pinned and the actual hex encoded SHA-256 hashes.
+	Expected string
+	Actual   string
+}
+
+// MARK: Initializers
+
+// NewCounts creates and returns the counts of the world.
+func NewCounts(world *models.World) *Counts {
+	counts := &Counts{Locations: len(world.Locations)}
+
+	dates := make(map[time.Time]bool)
+	for _, l := range world.Locations {
+		counts.Rows += len(l.Records)
+		for _, r := range l.Records {
+			dates[r.Date] = true
+		}
+	}
+	counts.Dates = len(dates)
+
+	if len(world.Records) > 0 {
+		counts.FirstDate = world.Records[0].Date.Format("2006-01-02")
+		counts.LastDate = world.Records[len(world.Records)-1].Date.Format("2006-01-02")
+	}
+
+	return counts
+}
+
+// MARK: Exported functions
+
+// Path returns the path of the metadata of the dataset file at path.
+func Path(path string) string {
+	return path + metadataSuffix
+}
+
+// Read reads the metadata of the dataset file at path.
+func Read(path string) (*Metadata, error) {
+	data, err := ioutil.ReadFile(Path(path))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+	if err = json.Unmarshal(data, metadata); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// Write writes the metadata of the dataset file at path.
+func Write(path string, metadata *Metadata) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(Path(path), data, 0644)
+}
+
+// HashFile returns the hex encoded SHA-256 hash of the file at path.
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyChecksum returns a *ChecksumError if the hash of the file with the
+// given name at path isn't the pinned checksum. Checksums are compared
+// case-insensitively.
+func VerifyChecksum(name string, path string, checksum string) error {
+	hash, err := HashFile(path)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(hash, checksum) {
+		return &ChecksumError{File: name, Expected: strings.ToLower(checksum), Actual: hash}
+	}
+
+	return nil
+}
+
+// MARK: Error interface methods
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("the SHA-256 checksum of %s is %s but %s is pinned", e.File, e.Actual, e.Expected)
+}
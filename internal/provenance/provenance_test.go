@@ -0,0 +1,118 @@
+package provenance
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/fetch"
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	if err = ioutil.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const hash = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+
+	tests := []struct {
+		name     string
+		checksum string
+		err      error
+	}{
+		{"match", hash, nil},
+		{"upper case", strings.ToUpper(hash), nil},
+		{"mismatch", strings.Repeat("0", 64), &ChecksumError{File: "data.csv", Expected: strings.Repeat("0", 64), Actual: hash}},
+		{"upper case mismatch", strings.Repeat("A", 64), &ChecksumError{File: "data.csv", Expected: strings.Repeat("a", 64), Actual: hash}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := VerifyChecksum("data.csv", path, test.checksum); !reflect.DeepEqual(err, test.err) {
+				t.Errorf("VerifyChecksum = %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	if err = VerifyChecksum("missing.csv", filepath.Join(dir, "missing.csv"), hash); !os.IsNotExist(err) {
+		t.Errorf("VerifyChecksum of a missing file = %v, want a not exist error", err)
+	}
+}
+
+func TestMetadataReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "provenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.csv")
+	if _, err = Read(path); !os.IsNotExist(err) {
+		t.Errorf("Read without metadata = %v, want a not exist error", err)
+	}
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	metadata := &Metadata{
+		File:       "data.csv",
+		URL:        "https://example.com/data.csv",
+		FetchedAt:  now,
+		CheckedAt:  now.Add(time.Hour),
+		Validators: fetch.Validators{ETag: `"v1"`, LastModified: "Sun, 01 Mar 2020 00:00:00 GMT"},
+		SHA256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		Size:       3,
+		Snapshot:   "20200301T120000Z",
+		Counts:     &Counts{Rows: 2, Locations: 1, Dates: 2, FirstDate: "2020-03-01", LastDate: "2020-03-02"},
+	}
+
+	if err = Write(path, metadata); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(Path(path)); err != nil {
+		t.Errorf("metadata isn't next to the dataset file: %v", err)
+	}
+
+	read, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, metadata) {
+		t.Errorf("Read = %+v, want %+v", read, metadata)
+	}
+}
+
+func TestNewCounts(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+	records := func(name string, days ...int) []*models.COVRecord {
+		var records []*models.COVRecord
+		for _, d := range days {
+			records = append(records, &models.COVRecord{Date: day(d), Location: name, NewCases: d, TotalCases: d})
+		}
+		return records
+	}
+
+	world := models.NewWorldFromLocations([]*models.Location{
+		models.NewLocation("Italy", records("Italy", 1, 2, 3)),
+		models.NewLocation("Spain", records("Spain", 2, 4)),
+	})
+
+	want := &Counts{Rows: 5, Locations: 2, Dates: 4, FirstDate: "2020-03-01", LastDate: "2020-03-04"}
+	if counts := NewCounts(world); !reflect.DeepEqual(counts, want) {
+		t.Errorf("NewCounts = %+v, want %+v", counts, want)
+	}
+
+	if counts := NewCounts(models.NewWorldFromLocations(nil)); !reflect.DeepEqual(counts, &Counts{}) {
+		t.Errorf("NewCounts of an empty world = %+v, want none", counts)
+	}
+}
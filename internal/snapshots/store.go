@@ -0,0 +1,357 @@
+// Package snapshots contains the archive of previously downloaded datasets.
+package snapshots
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The layout of the timestamp in snapshot file names.
+const timeLayout = "20060102T150405Z"
+
+// The extension of snapshot files.
+const snapshotExtension = ".gz"
+
+// Snapshot types describe an archived copy of a dataset file.
+type Snapshot struct {
+
+	// The name of the dataset file the snapshot is a copy of.
+	File string
+
+	// The time the snapshot was taken.
+	Time time.Time
+
+	// The hex encoded SHA-256 hash of the dataset file's contents.
+	Hash string
+
+	// The path of the compressed snapshot.
+	Path string
+
+	// The size of the compressed snapshot in bytes.
+	Size int64
+}
+
+// Store types archive snapshots of dataset files in a directory. Snapshots
+// are stored compressed in a subdirectory per dataset file and named by the
+// time they were taken and the hash of their contents.
+type Store struct {
+	dir string
+}
+
+// MARK: Initializers
+
+// NewStore creates and returns a new store in the given directory.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// MARK: Exported methods
+
+// Add archives the dataset file at path under the given file name as taken
+// at time t. A snapshot is only added when its contents differ from the
+// latest snapshot of the file, in which case the latest snapshot is returned
+// along with false.
+func (s *Store) Add(file string, path string, t time.Time) (*Snapshot, bool, error) {
+	hash, err := hashFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing, err := s.List(file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(existing) > 0 && existing[len(existing)-1].Hash == hash {
+		return existing[len(existing)-1], false, nil
+	}
+
+	dir := filepath.Join(s.dir, file)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, err
+	}
+
+	t = t.UTC()
+	snapshotPath := filepath.Join(dir, t.Format(timeLayout)+"-"+hash+snapshotExtension)
+	if err = compressFile(path, snapshotPath); err != nil {
+		return nil, false, err
+	}
+
+	info, err := os.Stat(snapshotPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &Snapshot{
+		File: file,
+		Time: t.Truncate(time.Second),
+		Hash: hash,
+		Path: snapshotPath,
+		Size: info.Size(),
+	}, true, nil
+}
+
+// Files returns the names of the dataset files that have snapshots.
+func (s *Store) Files() ([]string, error) {
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, info := range infos {
+		if info.IsDir() {
+			files = append(files, info.Name())
+		}
+	}
+	return files, nil
+}
+
+// List returns the snapshots of the given dataset file ordered from oldest
+// to newest.
+func (s *Store) List(file string) ([]*Snapshot, error) {
+	dir := filepath.Join(s.dir, file)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, info := range infos {
+		snapshot, ok := parseSnapshotName(info.Name())
+		if !ok {
+			continue
+		}
+
+		snapshot.File = file
+		snapshot.Path = filepath.Join(dir, info.Name())
+		snapshot.Size = info.Size()
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// All returns the snapshots of every dataset file.
+func (s *Store) All() ([]*Snapshot, error) {
+	files, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*Snapshot
+	for _, file := range files {
+		snapshots, err := s.List(file)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, snapshots...)
+	}
+	return all, nil
+}
+
+// AsOf returns the latest snapshot of the given dataset file taken at or
+// before time t.
+func (s *Store) AsOf(file string, t time.Time) (*Snapshot, error) {
+	snapshots, err := s.List(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var current *Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Time.After(t) {
+			break
+		}
+		current = snapshot
+	}
+
+	if current == nil {
+		return nil, fmt.Errorf("no snapshot of %s was taken at or before %s", file, t.Format(time.RFC3339))
+	}
+
+	return current, nil
+}
+
+// Find returns the snapshot whose hash starts with the given prefix.
+func (s *Store) Find(prefix string) (*Snapshot, error) {
+	if len(prefix) == 0 {
+		return nil, errors.New("empty snapshot id")
+	}
+
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Snapshot
+	for _, snapshot := range all {
+		if !strings.HasPrefix(snapshot.Hash, strings.ToLower(prefix)) {
+			continue
+		}
+
+		if found != nil && found.Path != snapshot.Path {
+			return nil, fmt.Errorf("snapshot id %q is ambiguous", prefix)
+		}
+		found = snapshot
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no snapshot with id %q", prefix)
+	}
+
+	return found, nil
+}
+
+// Prune removes snapshots of every dataset file taken before the given time,
+// always keeping at least the newest keep snapshots of each file. It returns
+// the removed snapshots.
+func (s *Store) Prune(keep int, before time.Time) ([]*Snapshot, error) {
+	files, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []*Snapshot
+	for _, file := range files {
+		snapshots, err := s.List(file)
+		if err != nil {
+			return removed, err
+		}
+
+		for i, snapshot := range snapshots {
+			if i >= len(snapshots)-keep || !snapshot.Time.Before(before) {
+				break
+			}
+
+			if err = os.Remove(snapshot.Path); err != nil {
+				return removed, err
+			}
+			removed = append(removed, snapshot)
+		}
+	}
+
+	return removed, nil
+}
+
+// Extract decompresses the snapshot to the given path.
+func (s Snapshot) Extract(path string) error {
+	in, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	reader, err := gzip.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, reader); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// ID returns the short identifier of the snapshot.
+func (s Snapshot) ID() string {
+	if len(s.Hash) > 12 {
+		return s.Hash[:12]
+	}
+	return s.Hash
+}
+
+// MARK: Unexported functions
+
+// parseSnapshotName parses the time and hash from a snapshot's file name.
+func parseSnapshotName(name string) (*Snapshot, bool) {
+	if !strings.HasSuffix(name, snapshotExtension) {
+		return nil, false
+	}
+
+	parts := strings.SplitN(strings.TrimSuffix(name, snapshotExtension), "-", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	t, err := time.Parse(timeLayout, parts[0])
+	if err != nil {
+		return nil, false
+	}
+
+	return &Snapshot{Time: t, Hash: parts[1]}, true
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file at path.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// compressFile compresses the file at path to a temporary file next to
+// destination and then renames it to destination.
+func compressFile(path string, destination string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := ioutil.TempFile(filepath.Dir(destination), ".snapshot-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+
+	writer := gzip.NewWriter(out)
+	if _, err = io.Copy(writer, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err = out.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(out.Name(), destination)
+}
@@ -0,0 +1,117 @@
+package sources
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/cache"
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// CachedSource types wrap a data source and cache its parsed world. The cache
+// is keyed by the hashes of the source's files and the parse options, and is
+// used in place of parsing the files while it is valid.
+//
+// When the files change, incremental sources only parse the rows that are new
+// or revised, and the differences from the previously cached world are
+// recorded next to the cache.
+type CachedSource struct {
+	DataSource
+
+	// The path of the cache file.
+	path string
+
+	// The changes made to the cache by the last parse.
+	changes *cache.Changes
+}
+
+// MARK: Initializers
+
+// NewCachedSource creates and returns a new cached source that stores the
+// world parsed by source at path.
+func NewCachedSource(source DataSource, path string) *CachedSource {
+	return &CachedSource{
+		DataSource: source,
+		path:       path,
+	}
+}
+
+// MARK: DataSource interface methods
+
+// Parse loads the world from the cache if it is valid, and otherwise parses
+// the data source's files in the given data directory and caches the result.
+// Sources without files aren't cached.
+func (s *CachedSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	s.changes = nil
+
+	files := s.Files()
+	if len(files) == 0 {
+		return s.DataSource.Parse(dir, options)
+	}
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = filepath.Join(dir, file.Name)
+	}
+
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, _ := cache.ReadKey(s.path)
+	key, err := cache.NewKey(s.Name(), string(encodedOptions), paths, previous)
+	if err != nil {
+		return s.DataSource.Parse(dir, options)
+	}
+
+	if world, ok := cache.Load(s.path, key); ok {
+		return world, nil
+	}
+
+	// Start from the previously cached world if it was parsed the same way
+	var base *models.World
+	var fingerprints models.Fingerprints
+	if previous != nil &&
+		previous.Version == key.Version &&
+		previous.Source == key.Source &&
+		previous.Options == key.Options {
+		if _, base, fingerprints, err = cache.Read(s.path); err != nil {
+			base = nil
+		}
+	}
+
+	var world *models.World
+	if incremental, ok := s.DataSource.(IncrementalSource); ok {
+		world, fingerprints, err = incremental.ParseIncrementally(dir, options, base, fingerprints)
+	} else {
+		world, err = s.DataSource.Parse(dir, options)
+		fingerprints = nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The cache is only an optimization, so failing to write it isn't an
+	// error.
+	if err = cache.Save(s.path, key, world, fingerprints); err == nil && base != nil {
+		s.changes = &cache.Changes{
+			Time:   time.Now(),
+			Source: s.Name(),
+			Diff:   models.CompareWorlds(base, world),
+		}
+		_ = cache.WriteChanges(s.path, s.changes)
+	}
+
+	return world, nil
+}
+
+// MARK: Exported methods
+
+// Changes returns the changes that the last parse made to the cached world,
+// or nil if the cached world was used or there was none before.
+func (s CachedSource) Changes() *cache.Changes {
+	return s.changes
+}
@@ -0,0 +1,52 @@
+package sources
+
+import (
+	"encoding/csv"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// rowDiagnostics types collect the problems with the rows of a source's
+// files, which are skipped when parsing leniently.
+type rowDiagnostics struct {
+	lenient     bool
+	diagnostics []models.Diagnostic
+}
+
+// MARK: Initializers
+
+// newRowDiagnostics creates and returns a new collection of row diagnostics
+// for parsing with the given options.
+func newRowDiagnostics(options *models.ParseOptions) *rowDiagnostics {
+	return &rowDiagnostics{lenient: options != nil && options.Lenient}
+}
+
+// MARK: Unexported methods
+
+// report reports the diagnostic and returns it as an error if parsing can't
+// continue, or nil if the row should be skipped.
+func (d *rowDiagnostics) report(diagnostic models.Diagnostic) error {
+	if !d.lenient {
+		return diagnostic
+	}
+
+	d.diagnostics = append(d.diagnostics, diagnostic)
+	return nil
+}
+
+// MARK: Unexported functions
+
+// rowDiagnostic returns the diagnostic of the row of the named file on the
+// given line that couldn't be parsed because of err.
+func rowDiagnostic(name string, line int, err error) models.Diagnostic {
+	diagnostic := models.Diagnostic{File: name, Line: line, Message: err.Error()}
+	switch err := err.(type) {
+	case *csv.ParseError:
+		diagnostic.Message = err.Err.Error()
+	case *models.FieldError:
+		diagnostic.Column = err.Column
+		diagnostic.Value = err.Value
+		diagnostic.Message = err.Err.Error()
+	}
+	return diagnostic
+}
@@ -0,0 +1,55 @@
+package sources
+
+import (
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestParseReadErrors(t *testing.T) {
+	failing := func(header string) io.Reader {
+		return io.MultiReader(strings.NewReader(header), failingReader{})
+	}
+
+	nytHeader := "date,state,fips,cases,deaths\n2020-03-01,New York,36,10,1\n"
+	jhuHeader := "Province/State,Country/Region,Lat,Long,3/1/20\n,Italy,41.8,12.5,1\n"
+	jhuUSHeader := "UID,Admin2,Province_State,Country_Region,3/1/20\n1,Kings,New York,US,4\n"
+	ecdcHeader := "dateRep,cases,deaths,countriesAndTerritories\n01/03/2020,3,1,Italy\n"
+
+	tests := []struct {
+		name  string
+		parse func(options *models.ParseOptions) (*models.World, error)
+	}{
+		{"nyt", func(options *models.ParseOptions) (*models.World, error) {
+			return ParseNYTRegions(failing(nytHeader), "us.csv", options)
+		}},
+		{"jhu", func(options *models.ParseOptions) (*models.World, error) {
+			return ParseJHUTimeSeries(failing(jhuHeader), "confirmed.csv", strings.NewReader(jhuHeader), "deaths.csv", options)
+		}},
+		{"jhu-us", func(options *models.ParseOptions) (*models.World, error) {
+			return ParseJHUUSTimeSeries(strings.NewReader(jhuUSHeader), "confirmed.csv", failing(jhuUSHeader), "deaths.csv", options)
+		}},
+		{"ecdc", func(options *models.ParseOptions) (*models.World, error) {
+			return ParseECDCCases(failing(ecdcHeader), "ecdc.csv", options)
+		}},
+	}
+
+	for _, test := range tests {
+		for _, lenient := range []bool{false, true} {
+			_, err := test.parse(&models.ParseOptions{Lenient: lenient})
+			if err == nil || !strings.Contains(err.Error(), syscall.EIO.Error()) {
+				t.Errorf("%s lenient %v error = %v, want the read error", test.name, lenient, err)
+			}
+		}
+	}
+}
+
+// failingReader types fail every read with EIO.
+type failingReader struct{}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	return 0, syscall.EIO
+}
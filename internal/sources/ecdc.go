@@ -0,0 +1,184 @@
+package sources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// ECDCSource types load the European Centre for Disease Prevention and
+// Control's geographic distribution of cases.
+type ECDCSource struct{}
+
+// MARK: Initializers
+
+func init() {
+	Register("ecdc", "European Centre for Disease Prevention and Control (opendata.ecdc.europa.eu)", func(argument string) (DataSource, error) {
+		return NewECDCSource(), nil
+	})
+}
+
+// NewECDCSource creates and returns a new ECDC source.
+func NewECDCSource() *ECDCSource {
+	return &ECDCSource{}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s ECDCSource) Name() string {
+	return "ecdc"
+}
+
+// Description returns a short, human readable description.
+func (s ECDCSource) Description() string {
+	return Describe(s.Name())
+}
+
+// Files returns the files that must be downloaded.
+func (s ECDCSource) Files() []File {
+	return []File{
+		File{
+			Name: "ecdc_case_distribution.csv",
+			URL:  "https://opendata.ecdc.europa.eu/covid19/casedistribution/csv",
+		},
+	}
+}
+
+// Parse parses the data source's files in the given data directory.
+func (s ECDCSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	path := filepath.Join(dir, s.Files()[0].Name)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseECDCCases(file, path, options)
+}
+
+// MARK: Exported functions
+
+// ParseECDCCases parses the ECDC's geographic distribution of cases. The
+// name is used in diagnostics.
+//
+// The file contains one row per location and day with the number of new
+// cases and deaths. Totals are accumulated from those and the world records
+// are the sum of every location.
+//
+// Rows that can't be parsed fail the parse with a Diagnostic error. When
+// parsing leniently those rows are skipped and reported as diagnostics of the
+// world instead.
+func ParseECDCCases(reader io.Reader, name string, options *models.ParseOptions) (*models.World, error) {
+	rowReader := models.NewRowReader(reader)
+
+	header, err := rowReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read header: %v", name, err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.TrimPrefix(column, "\ufeff")] = i
+	}
+	columnCount := len(header)
+
+	for _, column := range []string{"dateRep", "cases", "deaths", "countriesAndTerritories"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", name, column)
+		}
+	}
+
+	recordsByLocation := make(map[string][]*models.COVRecord)
+	diagnostics := newRowDiagnostics(options)
+	for {
+		record, err := rowReader.Read()
+		if err == io.EOF {
+			break
+		} else if _, malformed := err.(*csv.ParseError); err != nil && !malformed {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		var covRecord *models.COVRecord
+		if err == nil {
+			covRecord, err = parseECDCRow(record, columns, columnCount)
+		}
+
+		if err != nil {
+			if err = diagnostics.report(rowDiagnostic(name, rowReader.Line(), err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		recordsByLocation[covRecord.Location] = append(recordsByLocation[covRecord.Location], covRecord)
+	}
+
+	var locations []*models.Location
+	for location, records := range recordsByLocation {
+		sort.Slice(records, func(i, j int) bool { return records[i].Date.Before(records[j].Date) })
+
+		totalCases, totalDeaths := 0, 0
+		for _, r := range records {
+			totalCases += r.NewCases
+			totalDeaths += r.NewDeaths
+			r.TotalCases = totalCases
+			r.TotalDeaths = totalDeaths
+		}
+
+		locations = append(locations, models.NewLocation(location, records))
+	}
+
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+
+	world := models.NewWorldFromLocations(locations)
+	world.Diagnostics = diagnostics.diagnostics
+	return world, nil
+}
+
+// MARK: Unexported functions
+
+// parseECDCRow parses the record of a row of the ECDC's file. Empty new
+// cases and deaths are missing.
+func parseECDCRow(record []string, columns map[string]int, columnCount int) (*models.COVRecord, error) {
+	if len(record) != columnCount {
+		return nil, fmt.Errorf("expected %d columns but found %d", columnCount, len(record))
+	}
+
+	dateField := record[columns["dateRep"]]
+	date, err := time.Parse("02/01/2006", dateField)
+	if err != nil {
+		return nil, &models.FieldError{Column: "dateRep", Value: dateField, Err: errors.New("expected a date formatted as DD/MM/YYYY")}
+	}
+
+	covRecord := &models.COVRecord{
+		Date:     date,
+		Location: strings.Replace(record[columns["countriesAndTerritories"]], "_", " ", -1),
+		Missing:  unreportedMetrics,
+	}
+
+	for metric, column := range map[models.Metric]string{models.MetricNewCases: "cases", models.MetricNewDeaths: "deaths"} {
+		field := record[columns[column]]
+		if len(field) == 0 {
+			covRecord.SetMissing(metric)
+			continue
+		}
+
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, &models.FieldError{Column: column, Value: field, Err: errors.New("expected a number")}
+		}
+		covRecord.SetValue(metric, value)
+	}
+
+	return covRecord, nil
+}
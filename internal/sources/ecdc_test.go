@@ -0,0 +1,102 @@
+package sources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		spec string
+		name string
+		err  bool
+	}{
+		{"", DefaultSourceName, false},
+		{"ecdc", "ecdc", false},
+		{"JHU", "jhu", false},
+		{"nyt-counties", "nyt-counties", false},
+		{"local:/tmp/data.csv", "local", false},
+		{"local", "", true},
+		{"who", "", true},
+	}
+
+	for _, test := range tests {
+		source, err := New(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("New(%q) = %s, want an error", test.spec, source.Name())
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("New(%q) = %v", test.spec, err)
+		} else if source.Name() != test.name {
+			t.Errorf("New(%q) = %s, want %s", test.spec, source.Name(), test.name)
+		}
+	}
+}
+
+func TestParseECDCCases(t *testing.T) {
+	data := "\ufeffdateRep,day,month,year,cases,deaths,countriesAndTerritories,geoId\n" +
+		"02/03/2020,2,3,2020,5,,United_States_of_America,US\n" +
+		"01/03/2020,1,3,2020,3,1,United_States_of_America,US\n" +
+		"01/03/2020,1,3,2020,10,2,Italy,IT\n"
+
+	world, err := ParseECDCCases(strings.NewReader(data), "ecdc.csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := world.LocationNames(); !reflect.DeepEqual(names, []string{"Italy", "United States of America"}) {
+		t.Fatalf("locations = %v", names)
+	}
+
+	us := world.Location("United States of America")
+	if len(us.Records) != 2 || !us.Records[0].Date.Before(us.Records[1].Date) {
+		t.Fatalf("United States records aren't in date order")
+	}
+
+	if r := us.Records[1]; r.NewCases != 5 || r.TotalCases != 8 || !r.IsMissing(models.MetricNewDeaths) || r.TotalDeaths != 1 {
+		t.Errorf("second record = %+v", r)
+	}
+
+	if len(world.Records) != 2 || world.Records[0].TotalCases != 13 {
+		t.Errorf("world records aren't the sum of the locations")
+	}
+}
+
+func TestParseECDCCasesDiagnostics(t *testing.T) {
+	data := "dateRep,cases,deaths,countriesAndTerritories\n" +
+		"01/03/2020,3,1,Italy\n" +
+		"02/03/2020,4\n" +
+		"2020-03-03,5,1,Italy\n" +
+		"04/03/2020,many,1,Italy\n" +
+		"05/03/2020,6,1,Italy\n"
+
+	want := []models.Diagnostic{
+		{File: "ecdc.csv", Line: 3, Message: "expected 4 columns but found 2"},
+		{File: "ecdc.csv", Line: 4, Column: "dateRep", Value: "2020-03-03", Message: "expected a date formatted as DD/MM/YYYY"},
+		{File: "ecdc.csv", Line: 5, Column: "cases", Value: "many", Message: "expected a number"},
+	}
+
+	if _, err := ParseECDCCases(strings.NewReader(data), "ecdc.csv", nil); !reflect.DeepEqual(err, want[0]) {
+		t.Errorf("strict error = %v, want %v", err, want[0])
+	}
+
+	world, err := ParseECDCCases(strings.NewReader(data), "ecdc.csv", &models.ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(world.Diagnostics, want) {
+		t.Errorf("diagnostics = %v, want %v", world.Diagnostics, want)
+	}
+
+	if italy := world.Location("Italy"); len(italy.Records) != 2 || italy.Records[1].TotalCases != 9 {
+		t.Errorf("Italy's records don't skip the malformed rows")
+	}
+}
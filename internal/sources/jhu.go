@@ -0,0 +1,296 @@
+package sources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// The base URL of the JHU CSSE global time series files.
+const jhuBaseURL = "https://raw.githubusercontent.com/CSSEGISandData/COVID-19/master/csse_covid_19_data/csse_covid_19_time_series/"
+
+// JHUSource types load the Johns Hopkins University CSSE global time series.
+type JHUSource struct{}
+
+// jhuSeries types contain the cumulative values of a time series rolled up
+// by country.
+type jhuSeries struct {
+
+	// The dates of the series' columns.
+	dates []time.Time
+
+	// The cumulative values of each country keyed by country.
+	values map[string][]int
+
+	// Whether or not each country's value of each date is missing because
+	// one of the country's rows left it empty, keyed by country.
+	missing map[string][]bool
+}
+
+// MARK: Initializers
+
+func init() {
+	Register("jhu", "Johns Hopkins University CSSE global time series (github.com/CSSEGISandData)", func(argument string) (DataSource, error) {
+		return NewJHUSource(), nil
+	})
+}
+
+// NewJHUSource creates and returns a new JHU CSSE source.
+func NewJHUSource() *JHUSource {
+	return &JHUSource{}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s JHUSource) Name() string {
+	return "jhu"
+}
+
+// Description returns a short, human readable description.
+func (s JHUSource) Description() string {
+	return Describe(s.Name())
+}
+
+// Files returns the files that must be downloaded.
+func (s JHUSource) Files() []File {
+	return []File{
+		File{
+			Name: "time_series_covid19_confirmed_global.csv",
+			URL:  jhuBaseURL + "time_series_covid19_confirmed_global.csv",
+		},
+		File{
+			Name: "time_series_covid19_deaths_global.csv",
+			URL:  jhuBaseURL + "time_series_covid19_deaths_global.csv",
+		},
+	}
+}
+
+// Parse parses the data source's files in the given data directory.
+func (s JHUSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	files := s.Files()
+
+	confirmedPath := filepath.Join(dir, files[0].Name)
+	confirmed, err := os.Open(confirmedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer confirmed.Close()
+
+	deathsPath := filepath.Join(dir, files[1].Name)
+	deaths, err := os.Open(deathsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer deaths.Close()
+
+	return ParseJHUTimeSeries(confirmed, confirmedPath, deaths, deathsPath, options)
+}
+
+// MARK: Exported functions
+
+// ParseJHUTimeSeries parses the JHU CSSE confirmed cases and deaths time
+// series. The names of the series are used in diagnostics.
+//
+// The files contain one row per province or country with a column per date
+// holding cumulative values. Provinces are rolled up in to their countries,
+// the rows are pivoted in to one record per country and date, new cases and
+// deaths are the day-over-day change of the totals and the world records are
+// the sum of every country. Dates that any of a country's rows left empty
+// are missing, and the next reported total's change is from the last
+// reported total.
+//
+// Rows that can't be parsed fail the parse with a Diagnostic error. When
+// parsing leniently those rows are skipped and reported as diagnostics of the
+// world instead.
+func ParseJHUTimeSeries(confirmed io.Reader, confirmedName string, deaths io.Reader, deathsName string, options *models.ParseOptions) (*models.World, error) {
+	diagnostics := newRowDiagnostics(options)
+
+	confirmedSeries, err := readJHUSeries(confirmed, confirmedName, diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	deathsSeries, err := readJHUSeries(deaths, deathsName, diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	// The files are published together, but don't assume their columns line
+	// up.
+	deathsByDate := make(map[string]map[time.Time]int)
+	for country, values := range deathsSeries.values {
+		byDate := make(map[time.Time]int)
+		for i, date := range deathsSeries.dates {
+			if !deathsSeries.missing[country][i] {
+				byDate[date] = values[i]
+			}
+		}
+		deathsByDate[country] = byDate
+	}
+
+	var locations []*models.Location
+	for country, values := range confirmedSeries.values {
+		records := make([]*models.COVRecord, 0, len(values))
+		previousCases, previousDeaths := 0, 0
+
+		for i, date := range confirmedSeries.dates {
+			record := &models.COVRecord{
+				Date:     date,
+				Location: country,
+				Missing:  unreportedMetrics,
+			}
+
+			if confirmedSeries.missing[country][i] {
+				record.SetMissing(models.MetricTotalCases)
+				record.SetMissing(models.MetricNewCases)
+			} else {
+				record.TotalCases = values[i]
+				record.NewCases = values[i] - previousCases
+				previousCases = values[i]
+			}
+
+			if totalDeaths, ok := deathsByDate[country][date]; ok {
+				record.TotalDeaths = totalDeaths
+				record.NewDeaths = totalDeaths - previousDeaths
+				previousDeaths = totalDeaths
+			} else {
+				record.SetMissing(models.MetricTotalDeaths)
+				record.SetMissing(models.MetricNewDeaths)
+			}
+
+			records = append(records, record)
+		}
+
+		locations = append(locations, models.NewLocation(country, records))
+	}
+
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+
+	world := models.NewWorldFromLocations(locations)
+	world.Diagnostics = diagnostics.diagnostics
+	return world, nil
+}
+
+// MARK: Unexported functions
+
+// readJHUSeries reads a wide-format time series and sums its rows by country.
+// Rows that can't be parsed are reported to diagnostics.
+func readJHUSeries(reader io.Reader, name string, diagnostics *rowDiagnostics) (*jhuSeries, error) {
+	rowReader := models.NewRowReader(reader)
+
+	header, err := rowReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read header: %v", name, err)
+	}
+
+	countryColumn := -1
+	firstDateColumn := -1
+	var dateColumns []string
+	series := &jhuSeries{
+		values:  make(map[string][]int),
+		missing: make(map[string][]bool),
+	}
+
+	for i, column := range header {
+		column = strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")
+		if column == "Country/Region" || column == "Country_Region" {
+			countryColumn = i
+			continue
+		}
+
+		date, err := time.Parse("1/2/06", column)
+		if err != nil {
+			if firstDateColumn >= 0 {
+				return nil, fmt.Errorf("%s: unexpected column %q after the date columns", name, column)
+			}
+			continue
+		}
+
+		if firstDateColumn < 0 {
+			firstDateColumn = i
+		}
+		series.dates = append(series.dates, date)
+		dateColumns = append(dateColumns, column)
+	}
+	columnCount := len(header)
+
+	if countryColumn < 0 {
+		return nil, fmt.Errorf("%s: missing column %q", name, "Country/Region")
+	}
+
+	if firstDateColumn < 0 {
+		return nil, fmt.Errorf("%s: no date columns", name)
+	}
+
+	for {
+		record, err := rowReader.Read()
+		if err == io.EOF {
+			break
+		} else if _, malformed := err.(*csv.ParseError); err != nil && !malformed {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		var values []int
+		var missing []bool
+		if err == nil {
+			values, missing, err = parseJHURow(record, columnCount, firstDateColumn, dateColumns)
+		}
+
+		if err != nil {
+			if err = diagnostics.report(rowDiagnostic(name, rowReader.Line(), err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		country := record[countryColumn]
+		if _, ok := series.values[country]; !ok {
+			series.values[country] = make([]int, len(series.dates))
+			series.missing[country] = make([]bool, len(series.dates))
+		}
+
+		for i := range values {
+			series.values[country][i] += values[i]
+			series.missing[country][i] = series.missing[country][i] || missing[i]
+		}
+	}
+
+	return series, nil
+}
+
+// parseJHURow parses the cumulative values of a row of a wide-format time
+// series whose date columns, with the given names, start at firstDateColumn,
+// and whether or not each value is missing because its field is empty.
+func parseJHURow(record []string, columnCount int, firstDateColumn int, dateColumns []string) ([]int, []bool, error) {
+	if len(record) != columnCount {
+		return nil, nil, fmt.Errorf("expected %d columns but found %d", columnCount, len(record))
+	}
+
+	values := make([]int, len(dateColumns))
+	missing := make([]bool, len(dateColumns))
+	for i, column := range dateColumns {
+		field := record[firstDateColumn+i]
+		if len(field) == 0 {
+			missing[i] = true
+			continue
+		}
+
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, nil, &models.FieldError{Column: column, Value: field, Err: errors.New("expected a number")}
+		}
+		values[i] = value
+	}
+
+	return values, missing, nil
+}
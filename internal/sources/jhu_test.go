@@ -0,0 +1,96 @@
+package sources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestParseJHUTimeSeries(t *testing.T) {
+	confirmed := "Province/State,Country/Region,Lat,Long,3/1/20,3/2/20,3/3/20,3/4/20\n" +
+		",Italy,41.8,12.5,10,,30,35\n" +
+		"Hubei,China,30.9,112.2,100,110,120,130\n" +
+		"Guangdong,China,23.3,113.4,50,55,60,\n" +
+		"\"Bonaire, Sint Eustatius and Saba\",Netherlands,12.1,-68.2,1,1,2,2\n"
+	deaths := "Province/State,Country/Region,Lat,Long,3/1/20,3/2/20,3/3/20,3/4/20\n" +
+		",Italy,41.8,12.5,1,2,3,4\n" +
+		"Hubei,China,30.9,112.2,5,6,7,8\n" +
+		"Guangdong,China,23.3,113.4,0,0,1,1\n"
+
+	world, err := ParseJHUTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := world.LocationNames(); !reflect.DeepEqual(names, []string{"China", "Italy", "Netherlands"}) {
+		t.Fatalf("locations = %v", names)
+	}
+
+	tests := []struct {
+		location    string
+		totalCases  []int
+		newCases    []int
+		casesMissed []bool
+		totalDeaths []int
+		deathsMiss  bool
+	}{
+		{"Italy", []int{10, 0, 30, 35}, []int{10, 0, 20, 5}, []bool{false, true, false, false}, []int{1, 2, 3, 4}, false},
+		{"China", []int{150, 165, 180, 0}, []int{150, 15, 15, 0}, []bool{false, false, false, true}, []int{5, 6, 8, 9}, false},
+		{"Netherlands", []int{1, 1, 2, 2}, []int{1, 0, 1, 0}, []bool{false, false, false, false}, []int{0, 0, 0, 0}, true},
+	}
+
+	for _, test := range tests {
+		l := world.Location(test.location)
+		if len(l.Records) != len(test.totalCases) {
+			t.Errorf("%s has %d records, want %d", test.location, len(l.Records), len(test.totalCases))
+			continue
+		}
+
+		for i, r := range l.Records {
+			if r.IsMissing(models.MetricTotalCases) != test.casesMissed[i] || r.IsMissing(models.MetricNewCases) != test.casesMissed[i] {
+				t.Errorf("%s record %d cases missing = %v, want %v", test.location, i, r.IsMissing(models.MetricTotalCases), test.casesMissed[i])
+			}
+			if r.TotalCases != test.totalCases[i] || r.NewCases != test.newCases[i] {
+				t.Errorf("%s record %d cases = %d total and %d new, want %d and %d", test.location, i, r.TotalCases, r.NewCases, test.totalCases[i], test.newCases[i])
+			}
+			if r.IsMissing(models.MetricTotalDeaths) != test.deathsMiss || r.TotalDeaths != test.totalDeaths[i] {
+				t.Errorf("%s record %d total deaths = %d missing %v, want %d missing %v", test.location, i, r.TotalDeaths, r.IsMissing(models.MetricTotalDeaths), test.totalDeaths[i], test.deathsMiss)
+			}
+		}
+	}
+}
+
+func TestParseJHUTimeSeriesDiagnostics(t *testing.T) {
+	confirmed := "Province/State,Country/Region,Lat,Long,3/1/20,3/2/20\n" +
+		",Italy,41.8,12.5,10,20\n" +
+		"\"Multi\nLine\",France,46.2,2.2,5,x\n" +
+		",Spain,40.4,-3.7,1\n" +
+		",Germany,51.2,10.5,2,4\n"
+	deaths := "Province/State,Country/Region,Lat,Long,3/1/20,3/2/20\n" +
+		",Italy,41.8,12.5,1,2\n"
+
+	want := []models.Diagnostic{
+		{File: "confirmed.csv", Line: 3, Column: "3/2/20", Value: "x", Message: "expected a number"},
+		{File: "confirmed.csv", Line: 5, Message: "expected 6 columns but found 5"},
+	}
+
+	_, err := ParseJHUTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", nil)
+	if !reflect.DeepEqual(err, want[0]) {
+		t.Errorf("strict error = %v, want %v", err, want[0])
+	}
+
+	world, err := ParseJHUTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", &models.ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(world.Diagnostics, want) {
+		t.Errorf("diagnostics = %v, want %v", world.Diagnostics, want)
+	}
+
+	if names := world.LocationNames(); !reflect.DeepEqual(names, []string{"Germany", "Italy"}) {
+		t.Errorf("locations = %v, want the rows that could be parsed", names)
+	}
+}
@@ -0,0 +1,272 @@
+package sources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// JHUUSSource types load the Johns Hopkins University CSSE US time series of
+// counties, rolled up in to their states.
+type JHUUSSource struct{}
+
+// jhuUSRow types contain a row of a JHU CSSE US time series.
+type jhuUSRow struct {
+
+	// The names of the row's country, state and county. Rows of states
+	// without counties have an empty county.
+	path []string
+
+	// The population of the row's region, or -1 if the file doesn't have
+	// one.
+	population int
+
+	// The cumulative values of the series' dates.
+	values []int
+
+	// Whether or not each date's value is missing because its field is
+	// empty.
+	missing []bool
+}
+
+// MARK: Initializers
+
+func init() {
+	Register("jhu-us", "Johns Hopkins University CSSE US counties, rolled up in to states (github.com/CSSEGISandData)", func(argument string) (DataSource, error) {
+		return NewJHUUSSource(), nil
+	})
+}
+
+// NewJHUUSSource creates and returns a new JHU CSSE US source.
+func NewJHUUSSource() *JHUUSSource {
+	return &JHUUSSource{}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s JHUUSSource) Name() string {
+	return "jhu-us"
+}
+
+// Description returns a short, human readable description.
+func (s JHUUSSource) Description() string {
+	return Describe(s.Name())
+}
+
+// Files returns the files that must be downloaded.
+func (s JHUUSSource) Files() []File {
+	return []File{
+		File{
+			Name: "time_series_covid19_confirmed_US.csv",
+			URL:  jhuBaseURL + "time_series_covid19_confirmed_US.csv",
+		},
+		File{
+			Name: "time_series_covid19_deaths_US.csv",
+			URL:  jhuBaseURL + "time_series_covid19_deaths_US.csv",
+		},
+	}
+}
+
+// Parse parses the data source's files in the given data directory.
+func (s JHUUSSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	files := s.Files()
+
+	confirmedPath := filepath.Join(dir, files[0].Name)
+	confirmed, err := os.Open(confirmedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer confirmed.Close()
+
+	deathsPath := filepath.Join(dir, files[1].Name)
+	deaths, err := os.Open(deathsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer deaths.Close()
+
+	return ParseJHUUSTimeSeries(confirmed, confirmedPath, deaths, deathsPath, options)
+}
+
+// MARK: Exported functions
+
+// ParseJHUUSTimeSeries parses the JHU CSSE US confirmed cases and deaths time
+// series. The names of the series are used in diagnostics.
+//
+// The files contain one row per county, or per state for states and
+// territories without counties, with a column per date holding cumulative
+// values. The deaths file also has each region's population. Counties are
+// regions of their states, which are regions of their country. New cases and
+// deaths are the day-over-day change of the totals, and states and the
+// country are the sum of their regions.
+//
+// Rows that can't be parsed fail the parse with a Diagnostic error. When
+// parsing leniently those rows are skipped and reported as diagnostics of the
+// world instead.
+func ParseJHUUSTimeSeries(confirmed io.Reader, confirmedName string, deaths io.Reader, deathsName string, options *models.ParseOptions) (*models.World, error) {
+	diagnostics := newRowDiagnostics(options)
+
+	confirmedDates, confirmedRows, err := readJHUUSSeries(confirmed, confirmedName, diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	deathsDates, deathsRows, err := readJHUUSSeries(deaths, deathsName, diagnostics)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := newRegionBuilder()
+	add := func(dates []time.Time, rows []*jhuUSRow, metric models.Metric) {
+		for _, row := range rows {
+			values := make(map[models.Metric]int)
+			if row.population >= 0 {
+				values[models.MetricPopulation] = row.population
+			}
+
+			for i, date := range dates {
+				delete(values, metric)
+				if !row.missing[i] {
+					values[metric] = row.values[i]
+				}
+				builder.add(row.path, date, values)
+			}
+		}
+	}
+
+	// Populations are only in the deaths file, so they aren't summed twice
+	add(confirmedDates, confirmedRows, models.MetricTotalCases)
+	add(deathsDates, deathsRows, models.MetricTotalDeaths)
+
+	world := builder.world()
+	world.Diagnostics = diagnostics.diagnostics
+	return world, nil
+}
+
+// MARK: Unexported functions
+
+// readJHUUSSeries reads the dates and rows of a wide-format US time series.
+// Rows that can't be parsed are reported to diagnostics.
+func readJHUUSSeries(reader io.Reader, name string, diagnostics *rowDiagnostics) ([]time.Time, []*jhuUSRow, error) {
+	rowReader := models.NewRowReader(reader)
+
+	header, err := rowReader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: unable to read header: %v", name, err)
+	}
+
+	columns := make(map[string]int)
+	firstDateColumn := -1
+	var dates []time.Time
+	var dateColumns []string
+
+	for i, column := range header {
+		column = strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")
+
+		date, err := time.Parse("1/2/06", column)
+		if err != nil {
+			if firstDateColumn >= 0 {
+				return nil, nil, fmt.Errorf("%s: unexpected column %q after the date columns", name, column)
+			}
+			columns[column] = i
+			continue
+		}
+
+		if firstDateColumn < 0 {
+			firstDateColumn = i
+		}
+		dates = append(dates, date)
+		dateColumns = append(dateColumns, column)
+	}
+	columnCount := len(header)
+
+	for _, column := range []string{"Country_Region", "Province_State", "Admin2"} {
+		if _, ok := columns[column]; !ok {
+			return nil, nil, fmt.Errorf("%s: missing column %q", name, column)
+		}
+	}
+
+	if firstDateColumn < 0 {
+		return nil, nil, fmt.Errorf("%s: no date columns", name)
+	}
+
+	var rows []*jhuUSRow
+	for {
+		record, err := rowReader.Read()
+		if err == io.EOF {
+			break
+		} else if _, malformed := err.(*csv.ParseError); err != nil && !malformed {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		var row *jhuUSRow
+		if err == nil {
+			row, err = parseJHUUSRow(record, columns, columnCount, firstDateColumn, dateColumns)
+		}
+
+		if err != nil {
+			if err = diagnostics.report(rowDiagnostic(name, rowReader.Line(), err)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return dates, rows, nil
+}
+
+// parseJHUUSRow parses a row of a wide-format US time series whose date
+// columns, with the given names, start at firstDateColumn. Empty values are
+// missing.
+func parseJHUUSRow(record []string, columns map[string]int, columnCount int, firstDateColumn int, dateColumns []string) (*jhuUSRow, error) {
+	if len(record) != columnCount {
+		return nil, fmt.Errorf("expected %d columns but found %d", columnCount, len(record))
+	}
+
+	row := &jhuUSRow{
+		path: []string{
+			record[columns["Country_Region"]],
+			record[columns["Province_State"]],
+			record[columns["Admin2"]],
+		},
+		population: -1,
+		values:     make([]int, len(dateColumns)),
+		missing:    make([]bool, len(dateColumns)),
+	}
+
+	if populationColumn, ok := columns["Population"]; ok {
+		field := record[populationColumn]
+		population, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, &models.FieldError{Column: "Population", Value: field, Err: errors.New("expected a number")}
+		}
+		row.population = population
+	}
+
+	for i, column := range dateColumns {
+		field := record[firstDateColumn+i]
+		if len(field) == 0 {
+			row.missing[i] = true
+			continue
+		}
+
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, &models.FieldError{Column: column, Value: field, Err: errors.New("expected a number")}
+		}
+		row.values[i] = value
+	}
+
+	return row, nil
+}
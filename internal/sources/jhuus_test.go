@@ -0,0 +1,102 @@
+package sources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestParseJHUUSTimeSeries(t *testing.T) {
+	confirmed := "UID,Admin2,Province_State,Country_Region,Combined_Key,3/1/20,3/2/20\n" +
+		"1,Kings,New York,US,\"Kings, New York, US\",4,7\n" +
+		"2,Queens,New York,US,\"Queens, New York, US\",6,\n" +
+		"3,,Guam,US,\"Guam, US\",1,2\n"
+	deaths := "UID,Admin2,Province_State,Country_Region,Combined_Key,Population,3/1/20,3/2/20\n" +
+		"1,Kings,New York,US,\"Kings, New York, US\",2500000,0,1\n" +
+		"2,Queens,New York,US,\"Queens, New York, US\",2200000,1,1\n" +
+		"3,,Guam,US,\"Guam, US\",170000,0,0\n"
+
+	world, err := ParseJHUUSTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path        string
+		totalCases  []int
+		totalDeaths []int
+		population  int
+	}{
+		{"US/New York/Kings", []int{4, 7}, []int{0, 1}, 2500000},
+		{"US/New York/Queens", []int{6, 0}, []int{1, 1}, 2200000},
+		{"US/New York", []int{10, 13}, []int{1, 2}, 4700000},
+		{"US/Guam", []int{1, 2}, []int{0, 0}, 170000},
+		{"US", []int{11, 15}, []int{1, 2}, 4870000},
+	}
+
+	for _, test := range tests {
+		l := world.Location(test.path)
+		if l == nil {
+			t.Errorf("Location(%q) = nil", test.path)
+			continue
+		}
+
+		var totalCases, totalDeaths []int
+		for _, r := range l.Records {
+			totalCases = append(totalCases, r.TotalCases)
+			totalDeaths = append(totalDeaths, r.TotalDeaths)
+			if r.Population != test.population {
+				t.Errorf("%s population on %v = %d, want %d", test.path, r.Date, r.Population, test.population)
+			}
+		}
+
+		if !reflect.DeepEqual(totalCases, test.totalCases) || !reflect.DeepEqual(totalDeaths, test.totalDeaths) {
+			t.Errorf("%s totals = %v cases and %v deaths, want %v and %v", test.path, totalCases, totalDeaths, test.totalCases, test.totalDeaths)
+		}
+	}
+
+	// Empty cells are missing, so states carry their counties' last totals
+	if queens := world.Location("US/New York/Queens"); !queens.Records[1].IsMissing(models.MetricTotalCases) || !queens.Records[1].IsMissing(models.MetricNewCases) {
+		t.Errorf("Queens' empty total cases aren't missing")
+	}
+}
+
+func TestParseJHUUSTimeSeriesDiagnostics(t *testing.T) {
+	confirmed := "UID,Admin2,Province_State,Country_Region,3/1/20,3/2/20\n" +
+		"1,Kings,New York,US,4,7\n" +
+		"2,Queens,New York,US,6,x\n"
+	deaths := "UID,Admin2,Province_State,Country_Region,Population,3/1/20,3/2/20\n" +
+		"\n" +
+		"1,Kings,New York,US,unknown,0,1\n" +
+		"2,Queens,New York,US,2200000,1\n" +
+		"3,Bronx,New York,US,1400000,0,0\n"
+
+	want := []models.Diagnostic{
+		{File: "confirmed.csv", Line: 3, Column: "3/2/20", Value: "x", Message: "expected a number"},
+		{File: "deaths.csv", Line: 3, Column: "Population", Value: "unknown", Message: "expected a number"},
+		{File: "deaths.csv", Line: 4, Message: "expected 7 columns but found 6"},
+	}
+
+	_, err := ParseJHUUSTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", nil)
+	if !reflect.DeepEqual(err, want[0]) {
+		t.Errorf("strict error = %v, want %v", err, want[0])
+	}
+
+	world, err := ParseJHUUSTimeSeries(strings.NewReader(confirmed), "confirmed.csv", strings.NewReader(deaths), "deaths.csv", &models.ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(world.Diagnostics, want) {
+		t.Errorf("diagnostics = %v, want %v", world.Diagnostics, want)
+	}
+
+	if kings := world.Location("US/New York/Kings"); kings == nil || len(kings.Records) != 2 || kings.Records[1].TotalCases != 7 {
+		t.Errorf("Kings' confirmed cases weren't parsed")
+	}
+	if bronx := world.Location("US/New York/Bronx"); bronx == nil {
+		t.Errorf("rows after the malformed rows weren't parsed")
+	}
+}
@@ -0,0 +1,59 @@
+package sources
+
+import (
+	"errors"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// LocalSource types load a CSV file in the Our World in Data layout from an
+// arbitrary path.
+type LocalSource struct {
+	path string
+}
+
+// MARK: Initializers
+
+func init() {
+	Register("local", "A local CSV file in the owid layout, given as local:[path]", func(argument string) (DataSource, error) {
+		if len(argument) == 0 {
+			return nil, errors.New("the local data source requires a path, e.g. local:/path/to/data.csv")
+		}
+		return NewLocalSource(argument), nil
+	})
+}
+
+// NewLocalSource creates and returns a new local source for the given path.
+func NewLocalSource(path string) *LocalSource {
+	return &LocalSource{path: path}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s LocalSource) Name() string {
+	return "local"
+}
+
+// Description returns a short, human readable description.
+func (s LocalSource) Description() string {
+	return "Local file (" + s.path + ")"
+}
+
+// Files returns the files that must be downloaded, which local sources have
+// none of.
+func (s LocalSource) Files() []File {
+	return nil
+}
+
+// Parse parses the local file.
+func (s LocalSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	return models.NewWorldFromPathWithOptions(s.path, options)
+}
+
+// MARK: RecordSource interface methods
+
+// Records opens a reader of the records selected by filter.
+func (s LocalSource) Records(dir string, options *models.ParseOptions, filter *models.RecordFilter) (*models.RecordReader, error) {
+	return models.OpenRecordReader(s.path, options, filter)
+}
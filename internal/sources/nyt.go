@@ -0,0 +1,191 @@
+package sources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// The base URL of The New York Times' US files.
+const nytBaseURL = "https://raw.githubusercontent.com/nytimes/covid-19-data/master/"
+
+// The name of the country of The New York Times' regions.
+const nytCountry = "United States"
+
+// NYTSource types load The New York Times' cumulative cases and deaths of US
+// states, or of US counties rolled up in to their states.
+type NYTSource struct {
+	counties bool
+}
+
+// MARK: Initializers
+
+func init() {
+	Register("nyt", "The New York Times' US states (github.com/nytimes/covid-19-data)", func(argument string) (DataSource, error) {
+		return NewNYTSource(false), nil
+	})
+
+	Register("nyt-counties", "The New York Times' US counties, rolled up in to states (github.com/nytimes/covid-19-data)", func(argument string) (DataSource, error) {
+		return NewNYTSource(true), nil
+	})
+}
+
+// NewNYTSource creates and returns a new New York Times source of the US
+// states, or of the US counties if counties is true.
+func NewNYTSource(counties bool) *NYTSource {
+	return &NYTSource{counties: counties}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s NYTSource) Name() string {
+	if s.counties {
+		return "nyt-counties"
+	}
+	return "nyt"
+}
+
+// Description returns a short, human readable description.
+func (s NYTSource) Description() string {
+	return Describe(s.Name())
+}
+
+// Files returns the files that must be downloaded.
+func (s NYTSource) Files() []File {
+	name := "us-states.csv"
+	if s.counties {
+		name = "us-counties.csv"
+	}
+
+	return []File{
+		File{
+			Name: name,
+			URL:  nytBaseURL + name,
+		},
+	}
+}
+
+// Parse parses the data source's files in the given data directory.
+func (s NYTSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	path := filepath.Join(dir, s.Files()[0].Name)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseNYTRegions(file, path, options)
+}
+
+// MARK: Exported functions
+
+// ParseNYTRegions parses The New York Times' US states or counties file. The
+// name is used in diagnostics.
+//
+// The files contain one row per region and day with the region's cumulative
+// cases and deaths. The regions are states, or counties if the file has a
+// county column, of a single United States location. New cases and deaths
+// are the day-over-day change of the totals, and states and the country are
+// the sum of their regions.
+//
+// Rows that can't be parsed fail the parse with a Diagnostic error. When
+// parsing leniently those rows are skipped and reported as diagnostics of the
+// world instead.
+func ParseNYTRegions(reader io.Reader, name string, options *models.ParseOptions) (*models.World, error) {
+	rowReader := models.NewRowReader(reader)
+
+	header, err := rowReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to read header: %v", name, err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = i
+	}
+	columnCount := len(header)
+
+	for _, column := range []string{"date", "state", "cases", "deaths"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", name, column)
+		}
+	}
+
+	countyColumn, hasCounties := columns["county"]
+
+	builder := newRegionBuilder()
+	diagnostics := newRowDiagnostics(options)
+	for {
+		record, err := rowReader.Read()
+		if err == io.EOF {
+			break
+		} else if _, malformed := err.(*csv.ParseError); err != nil && !malformed {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+
+		var date time.Time
+		var values map[models.Metric]int
+		if err == nil {
+			date, values, err = parseNYTRow(record, columns, columnCount)
+		}
+
+		if err != nil {
+			if err = diagnostics.report(rowDiagnostic(name, rowReader.Line(), err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		path := []string{nytCountry, record[columns["state"]]}
+		if hasCounties {
+			path = append(path, record[countyColumn])
+		}
+
+		builder.add(path, date, values)
+	}
+
+	world := builder.world()
+	world.Diagnostics = diagnostics.diagnostics
+	return world, nil
+}
+
+// MARK: Unexported functions
+
+// parseNYTRow parses the date and cumulative values of a row of The New York
+// Times' files. Empty values are missing.
+func parseNYTRow(record []string, columns map[string]int, columnCount int) (time.Time, map[models.Metric]int, error) {
+	if len(record) != columnCount {
+		return time.Time{}, nil, fmt.Errorf("expected %d columns but found %d", columnCount, len(record))
+	}
+
+	dateField := record[columns["date"]]
+	date, err := time.Parse("2006-01-02", dateField)
+	if err != nil {
+		return time.Time{}, nil, &models.FieldError{Column: "date", Value: dateField, Err: errors.New("expected a date formatted as YYYY-MM-DD")}
+	}
+
+	values := make(map[models.Metric]int)
+	for metric, column := range map[models.Metric]string{models.MetricTotalCases: "cases", models.MetricTotalDeaths: "deaths"} {
+		field := record[columns[column]]
+		if len(field) == 0 {
+			continue
+		}
+
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return time.Time{}, nil, &models.FieldError{Column: column, Value: field, Err: errors.New("expected a number")}
+		}
+		values[metric] = value
+	}
+
+	return date, values, nil
+}
@@ -0,0 +1,112 @@
+package sources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestParseNYTRegions(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		totals map[string][]int
+	}{
+		{
+			name: "states",
+			data: "\ufeffdate,state,fips,cases,deaths\n" +
+				"2020-03-01,New York,36,10,1\n" +
+				"2020-03-01,Washington,53,5,2\n" +
+				"2020-03-02,New York,36,15,1\n" +
+				"2020-03-02,Washington,53,8,3\n",
+			totals: map[string][]int{
+				"United States":            {15, 23},
+				"United States/New York":   {10, 15},
+				"United States/Washington": {5, 8},
+			},
+		},
+		{
+			name: "counties",
+			data: "date,county,state,fips,cases,deaths\n" +
+				"2020-03-01,Kings,New York,36047,4,0\n" +
+				"2020-03-01,Queens,New York,36081,6,1\n" +
+				"2020-03-02,Kings,New York,36047,7,1\n" +
+				"2020-03-02,Queens,New York,36081,8,1\n" +
+				"2020-03-02,Unknown,New York,,2,0\n",
+			totals: map[string][]int{
+				"United States":                  {10, 17},
+				"United States/New York":         {10, 17},
+				"United States/New York/Kings":   {4, 7},
+				"United States/New York/Unknown": {2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world, err := ParseNYTRegions(strings.NewReader(test.data), "us.csv", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for path, totals := range test.totals {
+				l := world.Location(path)
+				if l == nil {
+					t.Errorf("Location(%q) = nil", path)
+					continue
+				}
+
+				var got []int
+				for _, r := range l.Records {
+					got = append(got, r.TotalCases)
+				}
+				if !reflect.DeepEqual(got, totals) {
+					t.Errorf("%s total cases = %v, want %v", path, got, totals)
+				}
+			}
+
+			if len(world.Diagnostics) > 0 {
+				t.Errorf("diagnostics = %v, want none", world.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestParseNYTRegionsDiagnostics(t *testing.T) {
+	data := "date,state,fips,cases,deaths\n" +
+		"2020-03-01,New York,36,10,1\n" +
+		"\n" +
+		"2020-03-01,\"Washington\nState\",53,5,2\n" +
+		"2020-03-02,New York,36,ten,1\n" +
+		"03/02/2020,Washington,53,8,3\n" +
+		"2020-03-02,Washington,53\n" +
+		"2020-03-02,Wash\"ington,53,8,3\n" +
+		"2020-03-03,New York,36,20,2\n"
+
+	want := []models.Diagnostic{
+		{File: "us.csv", Line: 6, Column: "cases", Value: "ten", Message: "expected a number"},
+		{File: "us.csv", Line: 7, Column: "date", Value: "03/02/2020", Message: "expected a date formatted as YYYY-MM-DD"},
+		{File: "us.csv", Line: 8, Message: "expected 5 columns but found 3"},
+		{File: "us.csv", Line: 9, Message: `bare " in non-quoted-field`},
+	}
+
+	if _, err := ParseNYTRegions(strings.NewReader(data), "us.csv", nil); !reflect.DeepEqual(err, want[0]) {
+		t.Errorf("strict error = %v, want %v", err, want[0])
+	}
+
+	world, err := ParseNYTRegions(strings.NewReader(data), "us.csv", &models.ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(world.Diagnostics, want) {
+		t.Errorf("diagnostics = %v, want %v", world.Diagnostics, want)
+	}
+
+	newYork := world.Location("United States/New York")
+	if newYork == nil || len(newYork.Records) != 2 || newYork.Records[1].NewCases != 10 {
+		t.Errorf("New York's records don't skip the malformed row")
+	}
+}
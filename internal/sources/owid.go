@@ -0,0 +1,86 @@
+package sources
+
+import (
+	"path/filepath"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// OWIDSource types load Our World in Data's full_data.csv or the extended
+// owid-covid-data.csv with testing, hospital, vaccination and population
+// columns.
+type OWIDSource struct {
+	extended bool
+}
+
+// MARK: Initializers
+
+func init() {
+	Register("owid", "Our World in Data cases and deaths (covid.ourworldindata.org)", func(argument string) (DataSource, error) {
+		return NewOWIDSource(false), nil
+	})
+
+	Register("owid-extended", "Our World in Data with tests, hospitalisations, vaccinations and population", func(argument string) (DataSource, error) {
+		return NewOWIDSource(true), nil
+	})
+}
+
+// NewOWIDSource creates and returns a new Our World in Data source. Extended
+// sources load the full OWID schema.
+func NewOWIDSource(extended bool) *OWIDSource {
+	return &OWIDSource{extended: extended}
+}
+
+// MARK: DataSource interface methods
+
+// Name returns the name the data source is registered with.
+func (s OWIDSource) Name() string {
+	if s.extended {
+		return "owid-extended"
+	}
+	return "owid"
+}
+
+// Description returns a short, human readable description.
+func (s OWIDSource) Description() string {
+	return Describe(s.Name())
+}
+
+// Files returns the files that must be downloaded.
+func (s OWIDSource) Files() []File {
+	if s.extended {
+		return []File{
+			File{
+				Name: "owid-covid-data.csv",
+				URL:  "https://covid.ourworldindata.org/data/owid-covid-data.csv",
+			},
+		}
+	}
+
+	return []File{
+		File{
+			Name: "covid_full_data.csv",
+			URL:  "https://covid.ourworldindata.org/data/full_data.csv",
+		},
+	}
+}
+
+// Parse parses the data source's files in the given data directory.
+func (s OWIDSource) Parse(dir string, options *models.ParseOptions) (*models.World, error) {
+	return models.NewWorldFromPathWithOptions(filepath.Join(dir, s.Files()[0].Name), options)
+}
+
+// MARK: IncrementalSource interface methods
+
+// ParseIncrementally parses the data source's files in the given data
+// directory, reusing the records of base whose rows are unchanged.
+func (s OWIDSource) ParseIncrementally(dir string, options *models.ParseOptions, base *models.World, fingerprints models.Fingerprints) (*models.World, models.Fingerprints, error) {
+	return models.UpdateWorldFromPath(base, fingerprints, filepath.Join(dir, s.Files()[0].Name), options)
+}
+
+// MARK: RecordSource interface methods
+
+// Records opens a reader of the records selected by filter.
+func (s OWIDSource) Records(dir string, options *models.ParseOptions, filter *models.RecordFilter) (*models.RecordReader, error) {
+	return models.OpenRecordReader(filepath.Join(dir, s.Files()[0].Name), options, filter)
+}
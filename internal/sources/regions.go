@@ -0,0 +1,141 @@
+package sources
+
+import (
+	"sort"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// regionBuilder types build a hierarchy of locations, such as a country's
+// states and their counties, from rows of the regions' cumulative values.
+type regionBuilder struct {
+	root *regionNode
+}
+
+// regionNode types contain a region and the records of its rows keyed by
+// date.
+type regionNode struct {
+	name     string
+	children map[string]*regionNode
+	records  map[time.Time]*models.COVRecord
+}
+
+// MARK: Initializers
+
+// newRegionBuilder creates and returns a new region builder.
+func newRegionBuilder() *regionBuilder {
+	return &regionBuilder{root: newRegionNode("")}
+}
+
+// newRegionNode creates and returns a new region node with the given name.
+func newRegionNode(name string) *regionNode {
+	return &regionNode{
+		name:     name,
+		children: make(map[string]*regionNode),
+		records:  make(map[time.Time]*models.COVRecord),
+	}
+}
+
+// MARK: Unexported methods
+
+// add adds the values of a row of the region with the given path, from the
+// top-level location down. Empty names at the end of the path are ignored,
+// so that rows of states without counties belong to the state. Metrics that
+// aren't in values are missing, and the values of rows of the same region and
+// date are summed.
+func (b *regionBuilder) add(path []string, date time.Time, values map[models.Metric]int) {
+	for len(path) > 0 && len(path[len(path)-1]) == 0 {
+		path = path[:len(path)-1]
+	}
+
+	node := b.root
+	for _, name := range path {
+		child, ok := node.children[name]
+		if !ok {
+			child = newRegionNode(name)
+			node.children[name] = child
+		}
+		node = child
+	}
+
+	record, ok := node.records[date]
+	if !ok {
+		record = &models.COVRecord{
+			Date:     date,
+			Location: node.name,
+			Missing:  models.AllMetrics(),
+		}
+		node.records[date] = record
+	}
+
+	for metric, value := range values {
+		if record.IsMissing(metric) {
+			record.Missing = record.Missing.Without(metric)
+			record.SetValue(metric, value)
+		} else {
+			record.SetValue(metric, record.Value(metric)+value)
+		}
+	}
+}
+
+// world creates and returns a world of the top-level regions. The records of
+// regions without rows of their own are the sum of their regions, and the
+// world's records are the sum of the top-level regions.
+func (b *regionBuilder) world() *models.World {
+	locations := b.root.locations()
+	for _, l := range locations {
+		l.RollUp()
+	}
+
+	return models.NewWorldFromLocations(locations)
+}
+
+// locations creates and returns the locations of the node's regions sorted by
+// name.
+func (n *regionNode) locations() []*models.Location {
+	var locations []*models.Location
+	for _, child := range n.children {
+		l := models.NewLocation(child.name, child.sortedRecords())
+		for _, region := range child.locations() {
+			l.AddChild(region)
+		}
+		locations = append(locations, l)
+	}
+
+	sort.Slice(locations, func(i, j int) bool { return locations[i].Name < locations[j].Name })
+	return locations
+}
+
+// sortedRecords returns the node's records in date order. New cases and
+// deaths are the change of the totals since the previous record that
+// reported them.
+func (n *regionNode) sortedRecords() []*models.COVRecord {
+	records := make([]*models.COVRecord, 0, len(n.records))
+	for _, r := range n.records {
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Date.Before(records[j].Date) })
+
+	previous := map[models.Metric]int{models.MetricTotalCases: 0, models.MetricTotalDeaths: 0}
+	newMetrics := map[models.Metric]models.Metric{
+		models.MetricTotalCases:  models.MetricNewCases,
+		models.MetricTotalDeaths: models.MetricNewDeaths,
+	}
+
+	for _, r := range records {
+		for total, newMetric := range newMetrics {
+			value, ok := r.Lookup(total)
+			if !ok {
+				r.SetMissing(newMetric)
+				continue
+			}
+
+			r.Missing = r.Missing.Without(newMetric)
+			r.SetValue(newMetric, value-previous[total])
+			previous[total] = value
+		}
+	}
+
+	return records
+}
@@ -0,0 +1,85 @@
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestRegionBuilderRollsUp(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+	values := func(cases, deaths int) map[models.Metric]int {
+		return map[models.Metric]int{models.MetricTotalCases: cases, models.MetricTotalDeaths: deaths}
+	}
+
+	builder := newRegionBuilder()
+	builder.add([]string{"United States", "New York", "Kings"}, day(1), values(10, 1))
+	builder.add([]string{"United States", "New York", "Queens"}, day(1), values(5, 0))
+	builder.add([]string{"United States", "New York", "Kings"}, day(2), values(15, 2))
+	builder.add([]string{"United States", "New York", "Queens"}, day(2), values(9, 1))
+
+	// States without counties have an empty county
+	builder.add([]string{"United States", "Guam", ""}, day(1), values(1, 0))
+	builder.add([]string{"United States", "Guam", ""}, day(2), map[models.Metric]int{models.MetricTotalCases: 3})
+
+	// Rows of the same region and date are summed
+	builder.add([]string{"United States", "New York", "Kings"}, day(2), values(1, 0))
+
+	world := builder.world()
+
+	tests := []struct {
+		path        string
+		children    int
+		totalCases  []int
+		newCases    []int
+		totalDeaths []int
+	}{
+		{"United States/New York/Kings", 0, []int{10, 16}, []int{10, 6}, []int{1, 2}},
+		{"United States/New York/Queens", 0, []int{5, 9}, []int{5, 4}, []int{0, 1}},
+		{"United States/New York", 2, []int{15, 25}, []int{15, 10}, []int{1, 3}},
+		{"United States/Guam", 0, []int{1, 3}, []int{1, 2}, []int{0, 0}},
+		{"United States", 2, []int{16, 28}, []int{16, 12}, []int{1, 3}},
+	}
+
+	for _, test := range tests {
+		l := world.Location(test.path)
+		if l == nil {
+			t.Errorf("Location(%q) = nil", test.path)
+			continue
+		}
+
+		if l.Path() != test.path {
+			t.Errorf("Location(%q).Path() = %q", test.path, l.Path())
+		}
+		if len(l.Children) != test.children {
+			t.Errorf("%s has %d regions, want %d", test.path, len(l.Children), test.children)
+		}
+		if len(l.Records) != len(test.totalCases) {
+			t.Errorf("%s has %d records, want %d", test.path, len(l.Records), len(test.totalCases))
+			continue
+		}
+
+		for i, r := range l.Records {
+			if !r.Date.Equal(day(i + 1)) {
+				t.Errorf("%s record %d date = %v, want %v", test.path, i, r.Date, day(i+1))
+			}
+			if r.TotalCases != test.totalCases[i] || r.NewCases != test.newCases[i] || r.TotalDeaths != test.totalDeaths[i] {
+				t.Errorf("%s record %d = total cases %d, new cases %d, total deaths %d, want %d, %d, %d", test.path, i, r.TotalCases, r.NewCases, r.TotalDeaths, test.totalCases[i], test.newCases[i], test.totalDeaths[i])
+			}
+		}
+	}
+
+	if guam := world.Location("United States/Guam"); guam != nil && len(guam.Records) == 2 {
+		if !guam.Records[1].IsMissing(models.MetricTotalDeaths) || !guam.Records[1].IsMissing(models.MetricNewDeaths) {
+			t.Errorf("Guam's unreported deaths aren't missing")
+		}
+		if guam.Records[1].IsMissing(models.MetricNewCases) {
+			t.Errorf("Guam's new cases are missing")
+		}
+	}
+
+	if len(world.Locations) != 1 || len(world.Records) != 2 || world.Records[1].TotalCases != 28 {
+		t.Errorf("world = %d locations and %d records, want the sum of the United States", len(world.Locations), len(world.Records))
+	}
+}
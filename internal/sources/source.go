@@ -0,0 +1,127 @@
+// Package sources contains the upstream providers of COVID-19 datasets.
+package sources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// DefaultSourceName is the name of the data source used when none is given.
+const DefaultSourceName = "owid"
+
+// File types describe a file that a data source downloads.
+type File struct {
+
+	// The file's name inside of the data directory.
+	Name string
+
+	// The URL the file is downloaded from.
+	URL string
+}
+
+// DataSource types fetch and parse a COVID-19 dataset.
+type DataSource interface {
+
+	// Name returns the name the data source is registered with.
+	Name() string
+
+	// Description returns a short, human readable description.
+	Description() string
+
+	// Files returns the files that must be downloaded in to the data
+	// directory before the data source can be parsed.
+	Files() []File
+
+	// Parse parses the data source's files in the given data directory.
+	// Sources with a fixed layout may ignore the parse options.
+	Parse(dir string, options *models.ParseOptions) (*models.World, error)
+}
+
+// RecordSource types are data sources whose records can be read one at a
+// time without loading the world.
+type RecordSource interface {
+	DataSource
+
+	// Records opens a reader of the records selected by filter in the data
+	// source's files in the given data directory.
+	Records(dir string, options *models.ParseOptions, filter *models.RecordFilter) (*models.RecordReader, error)
+}
+
+// IncrementalSource types are data sources that can reuse the records of a
+// previous parse whose rows haven't changed.
+type IncrementalSource interface {
+	DataSource
+
+	// ParseIncrementally parses the data source's files in the given data
+	// directory, reusing the records of base whose rows are unchanged. The
+	// fingerprints identify the rows of base, and the fingerprints of the
+	// parsed world's rows are returned with it. Base may be nil.
+	ParseIncrementally(dir string, options *models.ParseOptions, base *models.World, fingerprints models.Fingerprints) (*models.World, models.Fingerprints, error)
+}
+
+// Constructor types create data sources with an optional argument.
+type Constructor func(argument string) (DataSource, error)
+
+// registration types contain a registered data source.
+type registration struct {
+	description string
+	constructor Constructor
+}
+
+// The metrics that sources which only publish cases and deaths don't report.
+var unreportedMetrics = models.AllMetrics().Without(models.DefaultMetrics...)
+
+// The registered data sources keyed by name.
+var registry = make(map[string]registration)
+
+// MARK: Exported functions
+
+// Register registers a data source constructor with the given name and
+// description.
+func Register(name string, description string, constructor Constructor) {
+	registry[strings.ToLower(name)] = registration{
+		description: description,
+		constructor: constructor,
+	}
+}
+
+// Describe returns the description of the registered data source with the
+// given name.
+func Describe(name string) string {
+	return registry[strings.ToLower(name)].description
+}
+
+// Names returns the names of the registered data sources.
+func Names() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the data source for the given specification. A specification
+// is a registered name optionally followed by a colon and an argument, e.g.
+// "owid" or "local:/path/to/data.csv". An empty specification creates the
+// default data source.
+func New(spec string) (DataSource, error) {
+	if len(spec) == 0 {
+		spec = DefaultSourceName
+	}
+
+	name, argument := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, argument = spec[:i], spec[i+1:]
+	}
+
+	registration, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown data source %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+
+	return registration.constructor(argument)
+}
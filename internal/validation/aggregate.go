@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// The name of the world aggregate check.
+const worldAggregateRule = "world-aggregate"
+
+// MARK: Exported functions
+
+// CheckWorldAggregate compares the world records provided by the dataset
+// with the sum of its locations and returns an issue for every value that
+// differs. Worlds whose records were synthesized from their locations have no
+// issues.
+func CheckWorldAggregate(world *models.World) []Issue {
+	if world.Synthesized {
+		return nil
+	}
+
+	var issues []Issue
+	for _, change := range world.CheckAggregate() {
+		message := fmt.Sprintf("%s is %s but the locations sum to %s", change.Metric.Name(), formatValue(change.Old), formatValue(change.New))
+		if change.Old != nil && change.New != nil {
+			message += fmt.Sprintf(" (%+d)", *change.Old-*change.New)
+		}
+
+		issues = append(issues, Issue{
+			Rule:     worldAggregateRule,
+			Severity: SeverityWarning,
+			Location: "World",
+			Date:     change.Date,
+			Message:  message,
+		})
+	}
+	return issues
+}
+
+// MARK: Unexported functions
+
+// formatValue formats an optional value.
+func formatValue(value *int) string {
+	if value == nil {
+		return "missing"
+	}
+	return fmt.Sprintf("%d", *value)
+}
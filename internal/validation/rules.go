@@ -0,0 +1,211 @@
+package validation
+
+import (
+	"fmt"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// The number of days between consecutive records.
+const day = 24 * 60 * 60
+
+// NegativeValuesRule types find negative values. Negative new values are
+// usually upstream corrections and are warnings while negative totals are
+// errors.
+type NegativeValuesRule struct{}
+
+// DecreasingTotalsRule types find cumulative totals that decrease from one
+// record to the next.
+type DecreasingTotalsRule struct{}
+
+// InconsistentNewValuesRule types find new cases and deaths that don't
+// equal the day-over-day change of their totals.
+type InconsistentNewValuesRule struct{}
+
+// DateOrderRule types find duplicate and out of order dates.
+type DateOrderRule struct{}
+
+// DateGapsRule types find missing days between records.
+type DateGapsRule struct{}
+
+// MARK: Exported functions
+
+// DefaultRules returns the default set of rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		NegativeValuesRule{},
+		DecreasingTotalsRule{},
+		InconsistentNewValuesRule{},
+		DateOrderRule{},
+		DateGapsRule{},
+	}
+}
+
+// MARK: Rule interface methods
+
+// Name returns the rule's name.
+func (r NegativeValuesRule) Name() string {
+	return "negative-values"
+}
+
+// Check returns the issues found in the location's records.
+func (r NegativeValuesRule) Check(location *models.Location) []Issue {
+	var issues []Issue
+	for _, record := range location.Records {
+		for _, metric := range models.Metrics() {
+			value, ok := record.Lookup(metric)
+			if !ok || value >= 0 {
+				continue
+			}
+
+			severity := SeverityWarning
+			if metric.Cumulative() {
+				severity = SeverityError
+			}
+
+			issues = append(issues, Issue{
+				Rule:     r.Name(),
+				Severity: severity,
+				Location: location.Name,
+				Date:     record.Date,
+				Message:  fmt.Sprintf("%s is negative (%d)", metric.Name(), value),
+			})
+		}
+	}
+	return issues
+}
+
+// Name returns the rule's name.
+func (r DecreasingTotalsRule) Name() string {
+	return "decreasing-totals"
+}
+
+// Check returns the issues found in the location's records.
+func (r DecreasingTotalsRule) Check(location *models.Location) []Issue {
+	var issues []Issue
+	for _, metric := range models.Metrics() {
+		if !metric.Cumulative() || metric == models.MetricPopulation {
+			continue
+		}
+
+		previous, hasPrevious := 0, false
+		for _, record := range location.Records {
+			value, ok := record.Lookup(metric)
+			if !ok {
+				continue
+			}
+
+			if hasPrevious && value < previous {
+				issues = append(issues, Issue{
+					Rule:     r.Name(),
+					Severity: SeverityWarning,
+					Location: location.Name,
+					Date:     record.Date,
+					Message:  fmt.Sprintf("%s decreased from %d to %d", metric.Name(), previous, value),
+				})
+			}
+
+			previous, hasPrevious = value, true
+		}
+	}
+	return issues
+}
+
+// Name returns the rule's name.
+func (r InconsistentNewValuesRule) Name() string {
+	return "inconsistent-new-values"
+}
+
+// Check returns the issues found in the location's records.
+func (r InconsistentNewValuesRule) Check(location *models.Location) []Issue {
+	pairs := [][2]models.Metric{
+		{models.MetricNewCases, models.MetricTotalCases},
+		{models.MetricNewDeaths, models.MetricTotalDeaths},
+		{models.MetricNewTests, models.MetricTotalTests},
+		{models.MetricNewVaccinations, models.MetricTotalVaccinations},
+	}
+
+	var issues []Issue
+	for i := 1; i < len(location.Records); i++ {
+		previous, record := location.Records[i-1], location.Records[i]
+		if record.Date.Unix()-previous.Date.Unix() != day {
+			continue
+		}
+
+		for _, pair := range pairs {
+			newValue, newOK := record.Lookup(pair[0])
+			total, totalOK := record.Lookup(pair[1])
+			previousTotal, previousOK := previous.Lookup(pair[1])
+			if !newOK || !totalOK || !previousOK || newValue == total-previousTotal {
+				continue
+			}
+
+			issues = append(issues, Issue{
+				Rule:     r.Name(),
+				Severity: SeverityWarning,
+				Location: location.Name,
+				Date:     record.Date,
+				Message:  fmt.Sprintf("%s is %d but %s changed by %d", pair[0].Name(), newValue, pair[1].Name(), total-previousTotal),
+			})
+		}
+	}
+	return issues
+}
+
+// Name returns the rule's name.
+func (r DateOrderRule) Name() string {
+	return "date-order"
+}
+
+// Check returns the issues found in the location's records.
+func (r DateOrderRule) Check(location *models.Location) []Issue {
+	var issues []Issue
+	for i := 1; i < len(location.Records); i++ {
+		previous, record := location.Records[i-1], location.Records[i]
+
+		message := ""
+		if record.Date.Equal(previous.Date) {
+			message = "duplicate date"
+		} else if record.Date.Before(previous.Date) {
+			message = fmt.Sprintf("date is before the previous record's date %s", previous.Date.Format("2006-01-02"))
+		} else {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Rule:     r.Name(),
+			Severity: SeverityError,
+			Location: location.Name,
+			Date:     record.Date,
+			Message:  message,
+		})
+	}
+	return issues
+}
+
+// Name returns the rule's name.
+func (r DateGapsRule) Name() string {
+	return "date-gaps"
+}
+
+// Check returns the issues found in the location's records.
+func (r DateGapsRule) Check(location *models.Location) []Issue {
+	var issues []Issue
+	for i := 1; i < len(location.Records); i++ {
+		previous, record := location.Records[i-1], location.Records[i]
+
+		days := (record.Date.Unix() - previous.Date.Unix()) / day
+		if days <= 1 {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Rule:     r.Name(),
+			Severity: SeverityInfo,
+			Location: location.Name,
+			Date:     record.Date,
+			Message:  fmt.Sprintf("%d day(s) missing since %s", days-1, previous.Date.Format("2006-01-02")),
+		})
+	}
+	return issues
+}
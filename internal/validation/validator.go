@@ -0,0 +1,195 @@
+// Package validation contains the rules that check datasets for anomalies.
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+// Severity types classify issues.
+type Severity int
+
+// The severities of issues from least to most severe.
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// The names of the severities indexed by severity.
+var severityNames = []string{
+	SeverityInfo:    "info",
+	SeverityWarning: "warning",
+	SeverityError:   "error",
+}
+
+// Issue types describe a problem found in a location's records.
+type Issue struct {
+
+	// The name of the rule that found the issue.
+	Rule string `json:"rule"`
+
+	// The severity of the issue.
+	Severity Severity `json:"severity"`
+
+	// The path of the location of the records with the issue, e.g.
+	// "United States/New York".
+	Location string `json:"location"`
+
+	// The date of the record with the issue.
+	Date time.Time `json:"date"`
+
+	// A description of the issue.
+	Message string `json:"message"`
+}
+
+// Rule types check the records of a location.
+type Rule interface {
+
+	// Name returns the rule's name.
+	Name() string
+
+	// Check returns the issues found in the location's records, which are
+	// expected to be sorted by date.
+	Check(location *models.Location) []Issue
+}
+
+// Validator types check worlds with a set of rules.
+type Validator struct {
+	rules []Rule
+}
+
+// Report types contain the issues found by a validator.
+type Report struct {
+
+	// The number of locations and regions that were checked.
+	Locations int `json:"locations"`
+
+	// The number of records that were checked.
+	Records int `json:"records"`
+
+	// Whether or not the world's records were computed from its locations.
+	Synthesized bool `json:"synthesized"`
+
+	// The issues that were found ordered by location and date.
+	Issues []Issue `json:"issues"`
+}
+
+// MARK: Initializers
+
+// NewValidator creates and returns a new validator with the given rules, or
+// the default rules if none are given.
+func NewValidator(rules ...Rule) *Validator {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Validator{rules: rules}
+}
+
+// ParseSeverity returns the severity with the given case-insensitive name.
+func ParseSeverity(name string) (Severity, error) {
+	for i, severityName := range severityNames {
+		if severityName == strings.ToLower(name) {
+			return Severity(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown severity %q, expected one of %s", name, strings.Join(severityNames, ", "))
+}
+
+// MARK: Exported methods
+
+// Validate checks the world's records and the records of every location and
+// region. Issues of regions are reported with the region's path.
+func (v *Validator) Validate(world *models.World) *Report {
+	report := &Report{Issues: []Issue{}, Synthesized: world.Synthesized}
+
+	check := func(location *models.Location) {
+		report.Locations++
+		report.Records += len(location.Records)
+
+		for _, rule := range v.rules {
+			issues := rule.Check(location)
+			for i := range issues {
+				issues[i].Location = location.Path()
+			}
+			report.Issues = append(report.Issues, issues...)
+		}
+	}
+
+	check(models.NewLocation("World", world.Records))
+	for _, location := range world.Locations {
+		location.Walk(check)
+	}
+
+	report.sort()
+	return report
+}
+
+// Add adds the given issues to the report and keeps the issues ordered.
+func (r *Report) Add(issues []Issue) {
+	r.Issues = append(r.Issues, issues...)
+	r.sort()
+}
+
+// Count returns the number of issues with at least the given severity.
+func (r Report) Count(severity Severity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity >= severity {
+			count++
+		}
+	}
+	return count
+}
+
+// CountBySeverity returns the number of issues with exactly the given
+// severity.
+func (r Report) CountBySeverity(severity Severity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// CountByRule returns the number of issues found by each rule.
+func (r Report) CountByRule() map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range r.Issues {
+		counts[issue.Rule]++
+	}
+	return counts
+}
+
+// MARK: Unexported methods
+
+// sort orders the report's issues by location and date.
+func (r *Report) sort() {
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		a, b := r.Issues[i], r.Issues[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Date.Before(b.Date)
+	})
+}
+
+// MARK: String interface methods
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MARK: TextMarshaler interface methods
+
+// MarshalText encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
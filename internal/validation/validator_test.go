@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/colinc86/covid-19/internal/models"
+)
+
+func TestValidateRegions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+	records := func(name string, totals ...int) []*models.COVRecord {
+		var records []*models.COVRecord
+		previous := 0
+		for i, total := range totals {
+			records = append(records, &models.COVRecord{Date: day(i + 1), Location: name, NewCases: total - previous, TotalCases: total})
+			previous = total
+		}
+		return records
+	}
+
+	country := models.NewLocation("United States", nil)
+	state := models.NewLocation("New York", nil)
+	country.AddChild(state)
+	state.AddChild(models.NewLocation("Kings", records("Kings", 10, 8, 12)))
+	state.AddChild(models.NewLocation("Queens", records("Queens", 5, 9, 10)))
+	country.RollUp()
+
+	world := models.NewWorldFromLocations([]*models.Location{country})
+	report := NewValidator(DecreasingTotalsRule{}).Validate(world)
+
+	if report.Locations != 5 {
+		t.Errorf("checked %d locations, want the world, the country, the state and its 2 counties", report.Locations)
+	}
+
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Location)
+	}
+
+	want := []string{"United States/New York/Kings"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("issues of %v, want %v", got, want)
+	}
+}